@@ -0,0 +1,278 @@
+package analysis
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/vextasy/strategise/internal"
+)
+
+// ErrEmptyBenchmark is returned when a benchmark has no prices.
+var ErrEmptyBenchmark = errors.New("empty benchmark")
+
+// Benchmark is a reference price series, such as an index,
+// against which the results of a strategy are measured.
+type Benchmark struct {
+	// Name is the name of the benchmark as shown in reports.
+	Name string
+
+	dates    []time.Time // Ascending dates of the benchmark prices
+	closings []float64   // Closing prices matching dates
+}
+
+// NewBenchmark creates a benchmark from a stream of snapshots.
+func NewBenchmark(name string, snapshots <-chan *asset.Snapshot) (*Benchmark, error) {
+	b := &Benchmark{Name: name}
+	for s := range snapshots {
+		b.dates = append(b.dates, s.Date)
+		b.closings = append(b.closings, s.Close)
+	}
+	if len(b.dates) == 0 {
+		return nil, ErrEmptyBenchmark
+	}
+	sort.Sort(b)
+	return b, nil
+}
+
+// NewRepositoryBenchmark creates a benchmark from an asset held in the repository.
+func NewRepositoryBenchmark(r asset.Repository, name string) (*Benchmark, error) {
+	snapshots, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(name, snapshots)
+}
+
+// NewCsvBenchmark creates a benchmark from an external CSV file.
+// Each record holds a date in the asset.Snapshot date format followed by a closing price.
+// A first record that does not parse, such as a header, is ignored.
+func NewCsvBenchmark(name, path string) (*Benchmark, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	dateFormat, err := internal.GetStructTag(asset.Snapshot{}, "Date", "format")
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(fd)
+	reader.FieldsPerRecord = -1
+
+	c := make(chan *asset.Snapshot)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(c)
+		for line := 1; ; line++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				errc <- nil
+				return
+			}
+			if err == nil && len(record) < 2 {
+				err = fmt.Errorf("expected date and close")
+			}
+			var date time.Time
+			var close float64
+			if err == nil {
+				date, err = time.Parse(dateFormat, record[0])
+			}
+			if err == nil {
+				close, err = strconv.ParseFloat(record[1], 64)
+			}
+			if err != nil {
+				if line == 1 {
+					continue
+				}
+				errc <- fmt.Errorf("%s line %d: %w", path, line, err)
+				return
+			}
+			c <- &asset.Snapshot{Date: date, Open: close, High: close, Low: close, Close: close}
+		}
+	}()
+
+	b, berr := NewBenchmark(name, c)
+	if err := <-errc; err != nil {
+		return nil, err
+	}
+	return b, berr
+}
+
+// Len, Less and Swap implement sort.Interface over the benchmark prices.
+func (b *Benchmark) Len() int           { return len(b.dates) }
+func (b *Benchmark) Less(i, j int) bool { return b.dates[i].Before(b.dates[j]) }
+func (b *Benchmark) Swap(i, j int) {
+	b.dates[i], b.dates[j] = b.dates[j], b.dates[i]
+	b.closings[i], b.closings[j] = b.closings[j], b.closings[i]
+}
+
+// CloseAt returns the latest benchmark closing price on or before the given date.
+func (b *Benchmark) CloseAt(date time.Time) (float64, bool) {
+	i := sort.Search(len(b.dates), func(i int) bool {
+		return b.dates[i].After(date)
+	})
+	if i == 0 {
+		return 0, false
+	}
+	return b.closings[i-1], true
+}
+
+// BenchmarkMetrics describes the performance of a strategy relative to a benchmark.
+// Alpha, TrackingError and InformationRatio are annualised.
+type BenchmarkMetrics struct {
+	Return           float64 // Cumulative strategy return
+	BenchmarkReturn  float64 // Cumulative benchmark return
+	ExcessReturn     float64 // Return - BenchmarkReturn
+	Alpha            float64
+	Beta             float64
+	TrackingError    float64
+	InformationRatio float64
+}
+
+// ComputeBenchmarkMetrics compares daily strategy returns with the daily benchmark returns for the same days.
+func ComputeBenchmarkMetrics(returns, benchmarkReturns []float64) BenchmarkMetrics {
+	n := min(len(returns), len(benchmarkReturns))
+	returns, benchmarkReturns = returns[:n], benchmarkReturns[:n]
+
+	m := BenchmarkMetrics{
+		Return:          compound(returns),
+		BenchmarkReturn: compound(benchmarkReturns),
+	}
+	m.ExcessReturn = m.Return - m.BenchmarkReturn
+
+	if v := variance(benchmarkReturns); v != 0 {
+		m.Beta = covariance(returns, benchmarkReturns) / v
+	}
+	m.Alpha = (mean(returns) - m.Beta*mean(benchmarkReturns)) * TradingDaysPerYear
+
+	active := make([]float64, n)
+	for i := range active {
+		active[i] = returns[i] - benchmarkReturns[i]
+	}
+	m.TrackingError = stddev(active) * math.Sqrt(TradingDaysPerYear)
+	if m.TrackingError != 0 {
+		m.InformationRatio = mean(active) * TradingDaysPerYear / m.TrackingError
+	}
+	return m
+}
+
+// BenchmarkSeries holds the aligned daily series used to compare a strategy with a benchmark.
+type BenchmarkSeries struct {
+	Dates            []time.Time
+	Closings         []float64
+	Actions          []float64 // Normalized strategy actions as -1, 0 or 1
+	Returns          []float64 // Daily strategy returns
+	BenchmarkReturns []float64 // Daily benchmark returns
+}
+
+// Cumulative returns the running compounded value of the daily returns as a percentage.
+func Cumulative(returns []float64) []float64 {
+	result := make([]float64, len(returns))
+	growth := 1.0
+	for i, r := range returns {
+		growth *= 1 + r
+		result[i] = (growth - 1) * 100
+	}
+	return result
+}
+
+// Excess returns the running difference between the cumulative strategy and benchmark returns as a percentage.
+func (s *BenchmarkSeries) Excess() []float64 {
+	strategy := Cumulative(s.Returns)
+	benchmark := Cumulative(s.BenchmarkReturns)
+	result := make([]float64, len(strategy))
+	for i := range result {
+		result[i] = strategy[i] - benchmark[i]
+	}
+	return result
+}
+
+// Metrics computes the benchmark metrics for the series.
+func (s *BenchmarkSeries) Metrics() BenchmarkMetrics {
+	return ComputeBenchmarkMetrics(s.Returns, s.BenchmarkReturns)
+}
+
+// Last returns a copy of the series restricted to its last days entries.
+// A non-positive days value leaves the series unchanged.
+func (s *BenchmarkSeries) Last(days int) *BenchmarkSeries {
+	if days <= 0 || days >= len(s.Dates) {
+		return s
+	}
+	from := len(s.Dates) - days
+	return &BenchmarkSeries{
+		Dates:            s.Dates[from:],
+		Closings:         s.Closings[from:],
+		Actions:          s.Actions[from:],
+		Returns:          s.Returns[from:],
+		BenchmarkReturns: s.BenchmarkReturns[from:],
+	}
+}
+
+// Align builds the daily strategy and benchmark returns for the given snapshots and
+// the normalized actions a strategy computed from them.
+// Days for which the benchmark has no price carry its previous price forward.
+func (b *Benchmark) Align(snapshots []*asset.Snapshot, actions []float64) *BenchmarkSeries {
+	s := &BenchmarkSeries{
+		Dates:            make([]time.Time, len(snapshots)),
+		Closings:         make([]float64, len(snapshots)),
+		Actions:          make([]float64, len(snapshots)),
+		Returns:          StrategyReturns(snapshots, actions),
+		BenchmarkReturns: make([]float64, len(snapshots)),
+	}
+	copy(s.Actions, actions)
+
+	var lastBenchmark float64
+	for i, snapshot := range snapshots {
+		s.Dates[i] = snapshot.Date
+		s.Closings[i] = snapshot.Close
+
+		benchmark, ok := b.CloseAt(snapshot.Date)
+		if ok && lastBenchmark != 0 {
+			s.BenchmarkReturns[i] = benchmark/lastBenchmark - 1
+		}
+		if ok {
+			lastBenchmark = benchmark
+		}
+	}
+	return s
+}
+
+// Report creates a report charting the strategy against the benchmark.
+// Chart 0 holds the closing prices and actions, chart 1 the cumulative returns
+// of the strategy and the benchmark, and chart 2 the excess return.
+func (s *BenchmarkSeries) Report(title string, benchmarkName string) *helper.Report {
+	annotations := make([]string, len(s.Actions))
+	for i, a := range s.Actions {
+		switch {
+		case a > 0:
+			annotations[i] = "B"
+		case a < 0:
+			annotations[i] = "S"
+		}
+	}
+
+	report := helper.NewReport(title, helper.SliceToChan(s.Dates))
+	report.AddChart()
+	report.AddChart()
+
+	report.AddColumn(helper.NewNumericReportColumn("Close", helper.SliceToChan(s.Closings)))
+	report.AddColumn(helper.NewAnnotationReportColumn(helper.SliceToChan(annotations)), 0)
+
+	report.AddColumn(helper.NewNumericReportColumn("Strategy", helper.SliceToChan(Cumulative(s.Returns))), 1)
+	report.AddColumn(helper.NewNumericReportColumn(benchmarkName, helper.SliceToChan(Cumulative(s.BenchmarkReturns))), 1)
+
+	report.AddColumn(helper.NewNumericReportColumn("Excess", helper.SliceToChan(s.Excess())), 2)
+
+	return report
+}
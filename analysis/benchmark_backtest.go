@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/vextasy/strategise/internal"
+)
+
+// BenchmarkBacktest runs each strategy against each asset in the repository
+// and measures the results against a benchmark.
+type BenchmarkBacktest struct {
+	repository asset.Repository
+	benchmark  *Benchmark
+	outputDir  string
+
+	// Names of the assets to backtest. All assets in the repository when empty.
+	Names []string
+
+	// Strategies to backtest.
+	Strategies []strategy.Strategy
+
+	// LastDays is the number of most recent days to measure. All days when zero.
+	LastDays int
+}
+
+// BenchmarkResult is the outcome of backtesting one strategy against one asset.
+type BenchmarkResult struct {
+	Asset    string
+	Strategy string
+	Report   string // Report file name relative to the output directory
+	BenchmarkMetrics
+}
+
+// NewBenchmarkBacktest creates a benchmark backtest writing its reports to outputDir.
+func NewBenchmarkBacktest(repository asset.Repository, benchmark *Benchmark, outputDir string) *BenchmarkBacktest {
+	return &BenchmarkBacktest{
+		repository: repository,
+		benchmark:  benchmark,
+		outputDir:  outputDir,
+	}
+}
+
+// Run backtests the strategies, writes a report for each asset and strategy
+// and a summary page named benchmark.html, and returns the results.
+func (b *BenchmarkBacktest) Run() ([]BenchmarkResult, error) {
+	names := b.Names
+	if len(names) == 0 {
+		var err error
+		names, err = b.repository.Assets()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var results []BenchmarkResult
+	for _, name := range names {
+		snapshots, err := b.repository.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		snapshotSlice := helper.ChanToSlice(snapshots)
+
+		for _, st := range b.Strategies {
+			result, err := b.runStrategy(st, name, snapshotSlice)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, b.writeSummary(results)
+}
+
+// runStrategy measures a single strategy on a single asset and writes its report.
+func (b *BenchmarkBacktest) runStrategy(st strategy.Strategy, name string, snapshots []*asset.Snapshot) (BenchmarkResult, error) {
+	series := b.benchmark.Align(snapshots, ComputeActions(st, snapshots)).Last(b.LastDays)
+
+	cfn := internal.CleanFilename
+	result := BenchmarkResult{
+		Asset:            name,
+		Strategy:         st.Name(),
+		Report:           fmt.Sprintf("%s--%s--%s.html", cfn(name), cfn(st.Name()), cfn(b.benchmark.Name)),
+		BenchmarkMetrics: series.Metrics(),
+	}
+
+	title := fmt.Sprintf("%s - %s vs %s (Alpha %.2f, Beta %.2f, TE %.2f, IR %.2f)",
+		name, st.Name(), b.benchmark.Name,
+		result.Alpha, result.Beta, result.TrackingError, result.InformationRatio,
+	)
+	err := series.Report(title, b.benchmark.Name).WriteToFile(filepath.Join(b.outputDir, result.Report))
+	return result, err
+}
+
+// benchmarkSummaryTemplate lists the results of a benchmark backtest.
+var benchmarkSummaryTemplate = template.Must(template.New("benchmark").Funcs(template.FuncMap{
+	"percent": func(v float64) string { return fmt.Sprintf("%.2f", v*100) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Benchmark {{ .Benchmark }}</title>
+<style>
+table { border-collapse: collapse; font-family: sans-serif; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+td:first-child, td:nth-child(2) { text-align: left; }
+</style>
+</head>
+<body>
+<h1>Strategies vs {{ .Benchmark }}</h1>
+<table>
+<tr><th>Asset</th><th>Strategy</th><th>Return %</th><th>Benchmark %</th><th>Excess %</th><th>Alpha</th><th>Beta</th><th>Tracking Error</th><th>Information Ratio</th></tr>
+{{- range .Results }}
+<tr><td>{{ .Asset }}</td><td><a href="{{ .Report }}">{{ .Strategy }}</a></td><td>{{ percent .Return }}</td><td>{{ percent .BenchmarkReturn }}</td><td>{{ percent .ExcessReturn }}</td><td>{{ printf "%.3f" .Alpha }}</td><td>{{ printf "%.3f" .Beta }}</td><td>{{ printf "%.3f" .TrackingError }}</td><td>{{ printf "%.3f" .InformationRatio }}</td></tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+// writeSummary writes the summary page for the results.
+func (b *BenchmarkBacktest) writeSummary(results []BenchmarkResult) error {
+	fd, err := os.Create(filepath.Join(b.outputDir, "benchmark.html"))
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	return benchmarkSummaryTemplate.Execute(fd, struct {
+		Benchmark string
+		Results   []BenchmarkResult
+	}{b.benchmark.Name, results})
+}
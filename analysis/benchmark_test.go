@@ -0,0 +1,192 @@
+package analysis
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+)
+
+// testDate is the date of the first test snapshot.
+var testDate = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestSnapshots returns a snapshot for each closing price, one day apart.
+func newTestSnapshots(closings ...float64) []*asset.Snapshot {
+	snapshots := make([]*asset.Snapshot, len(closings))
+	for i, close := range closings {
+		snapshots[i] = &asset.Snapshot{
+			Date:  testDate.AddDate(0, 0, i),
+			Open:  close,
+			High:  close,
+			Low:   close,
+			Close: close,
+		}
+	}
+	return snapshots
+}
+
+// equalFloats reports whether the values are equal to within rounding.
+func equalFloats(a, b []float64) bool {
+	return slices.EqualFunc(a, b, func(x, y float64) bool {
+		return math.Abs(x-y) < 1e-9
+	})
+}
+
+func TestComputeBenchmarkMetrics(t *testing.T) {
+	sqrtYear := math.Sqrt(TradingDaysPerYear)
+	tests := []struct {
+		name             string
+		returns          []float64
+		benchmarkReturns []float64
+		expected         BenchmarkMetrics
+	}{
+		{
+			"same as the benchmark",
+			[]float64{0.01, -0.02, 0.03},
+			[]float64{0.01, -0.02, 0.03},
+			BenchmarkMetrics{Return: 0.019494, BenchmarkReturn: 0.019494, Beta: 1},
+		},
+		{
+			"twice the benchmark",
+			[]float64{0.02, -0.04, 0.06},
+			[]float64{0.01, -0.02, 0.03},
+			BenchmarkMetrics{
+				Return:          0.037952,
+				BenchmarkReturn: 0.019494,
+				ExcessReturn:    0.018458,
+				Beta:            2,
+				Alpha:           0,
+				TrackingError:   stddev([]float64{0.01, -0.02, 0.03}) * sqrtYear,
+				// The mean active return is the benchmark's mean.
+				InformationRatio: 0.02 / 3 * TradingDaysPerYear / (stddev([]float64{0.01, -0.02, 0.03}) * sqrtYear),
+			},
+		},
+		{
+			"flat benchmark",
+			[]float64{0.02, 0, 0.01},
+			[]float64{0.01, 0.01, 0.01},
+			BenchmarkMetrics{
+				Return:          0.0302,
+				BenchmarkReturn: 0.030301,
+				ExcessReturn:    -0.000101,
+				Alpha:           0.01 * TradingDaysPerYear,
+				TrackingError:   0.01 * sqrtYear,
+			},
+		},
+		{
+			"longer strategy returns are cut to the benchmark's",
+			[]float64{0.1, 0.2},
+			[]float64{0.1},
+			// A single day has no variance, so all of the return is alpha.
+			BenchmarkMetrics{Return: 0.1, BenchmarkReturn: 0.1, Alpha: 0.1 * TradingDaysPerYear},
+		},
+	}
+	for _, test := range tests {
+		actual := ComputeBenchmarkMetrics(test.returns, test.benchmarkReturns)
+		fields := func(m BenchmarkMetrics) []float64 {
+			return []float64{m.Return, m.BenchmarkReturn, m.ExcessReturn, m.Alpha, m.Beta, m.TrackingError, m.InformationRatio}
+		}
+		if !slices.EqualFunc(fields(actual), fields(test.expected), func(x, y float64) bool {
+			return math.Abs(x-y) < 1e-6
+		}) {
+			t.Fatalf("%s: actual %+v expected %+v", test.name, actual, test.expected)
+		}
+	}
+}
+
+func TestBenchmarkAlign(t *testing.T) {
+	// The benchmark has no price on the second and fourth days.
+	benchmarkSnapshots := newTestSnapshots(200, 0, 210, 0)
+	benchmarkSnapshots = []*asset.Snapshot{benchmarkSnapshots[2], benchmarkSnapshots[0]}
+	b, err := NewBenchmark("Index", helper.SliceToChan(benchmarkSnapshots))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots := newTestSnapshots(10, 11, 12.1, 10.89)
+	s := b.Align(snapshots, []float64{1, 0, 0, -1})
+
+	if expected := []float64{0, 0.1, 0.1, -0.1}; !equalFloats(s.Returns, expected) {
+		t.Fatalf("actual returns %v expected %v", s.Returns, expected)
+	}
+	if expected := []float64{0, 0, 0.05, 0}; !equalFloats(s.BenchmarkReturns, expected) {
+		t.Fatalf("actual benchmark returns %v expected %v", s.BenchmarkReturns, expected)
+	}
+	if expected := []float64{0, 10, 21, 8.9}; !equalFloats(Cumulative(s.Returns), expected) {
+		t.Fatalf("actual cumulative returns %v expected %v", Cumulative(s.Returns), expected)
+	}
+	if expected := []float64{0, 10, 16, 3.9}; !equalFloats(s.Excess(), expected) {
+		t.Fatalf("actual excess %v expected %v", s.Excess(), expected)
+	}
+
+	last := s.Last(2)
+	if len(last.Dates) != 2 || !last.Dates[0].Equal(snapshots[2].Date) || !equalFloats(last.Returns, []float64{0.1, -0.1}) {
+		t.Fatalf("actual last days %+v", last)
+	}
+	if s.Last(0) != s || s.Last(10) != s {
+		t.Fatal("expected the whole series for zero or too many days")
+	}
+}
+
+func TestBenchmarkCloseAt(t *testing.T) {
+	b, err := NewBenchmark("Index", helper.SliceToChan(newTestSnapshots(100, 101)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		date  time.Time
+		close float64
+		ok    bool
+	}{
+		{testDate.AddDate(0, 0, -1), 0, false},
+		{testDate, 100, true},
+		{testDate.Add(12 * time.Hour), 100, true},
+		{testDate.AddDate(0, 0, 5), 101, true},
+	}
+	for _, test := range tests {
+		close, ok := b.CloseAt(test.date)
+		if close != test.close || ok != test.ok {
+			t.Fatalf("%v: actual %g, %v expected %g, %v", test.date, close, ok, test.close, test.ok)
+		}
+	}
+
+	if _, err := NewBenchmark("Empty", helper.SliceToChan([]*asset.Snapshot{})); !errors.Is(err, ErrEmptyBenchmark) {
+		t.Fatalf("actual error %v expected %v", err, ErrEmptyBenchmark)
+	}
+}
+
+func TestNewCsvBenchmark(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		csv string
+		ok  bool
+	}{
+		{"date,close\n2024-01-02,101\n2024-01-01,100\n", true},
+		{"2024-01-01,100\n2024-01-02,101\n", true},
+		{"date,close\n2024-01-01,100\n2024-01-02,abc\n", false},
+		{"date,close\n2024-01-01\n", false},
+		{"date,close\n", false},
+	}
+	for i, test := range tests {
+		path := filepath.Join(dir, "benchmark.csv")
+		if err := os.WriteFile(path, []byte(test.csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+		b, err := NewCsvBenchmark("Index", path)
+		if (err == nil) != test.ok {
+			t.Fatalf("case %d: actual error %v", i, err)
+		}
+		if !test.ok {
+			continue
+		}
+		if close, _ := b.CloseAt(testDate.AddDate(0, 0, 1)); b.Len() != 2 || close != 101 {
+			t.Fatalf("case %d: actual %d prices, last %g", i, b.Len(), close)
+		}
+	}
+}
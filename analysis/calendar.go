@@ -0,0 +1,99 @@
+package analysis
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/vextasy/strategise/internal"
+)
+
+// Calendar holds the closing prices of several assets on a shared calendar of dates.
+// Days on which an asset has no price carry its previous price forward,
+// and days before its first price hold zero.
+type Calendar struct {
+	Dates    []time.Time
+	Closings map[string][]float64
+
+	// Traded holds, for each asset, whether it has its own price on each day
+	// rather than one carried forward.
+	Traded map[string][]bool
+
+	index      map[string]int // Position of each formatted date in Dates
+	dateFormat string         // Date format used by the indicator library
+}
+
+// NewCalendar reads the closing prices of the named assets from the repository.
+func NewCalendar(r asset.Repository, names []string) (*Calendar, error) {
+	dateFormat, err := internal.GetStructTag(asset.Snapshot{}, "Date", "format")
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]map[string]float64, len(names))
+	dates := make(map[string]time.Time)
+	for _, name := range names {
+		snapshots, err := r.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		prices[name] = make(map[string]float64)
+		for s := range snapshots {
+			key := s.Date.Format(dateFormat)
+			prices[name][key] = s.Close
+			dates[key] = s.Date
+		}
+	}
+
+	keys := make([]string, 0, len(dates))
+	for key := range dates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	c := &Calendar{
+		Dates:      make([]time.Time, len(keys)),
+		Closings:   make(map[string][]float64, len(names)),
+		Traded:     make(map[string][]bool, len(names)),
+		index:      make(map[string]int, len(keys)),
+		dateFormat: dateFormat,
+	}
+	for i, key := range keys {
+		c.Dates[i] = dates[key]
+		c.index[key] = i
+	}
+
+	for _, name := range names {
+		closes := make([]float64, len(keys))
+		traded := make([]bool, len(keys))
+		last := 0.0
+		for i, key := range keys {
+			if price, ok := prices[name][key]; ok {
+				last = price
+				traded[i] = true
+			}
+			closes[i] = last
+		}
+		c.Closings[name] = closes
+		c.Traded[name] = traded
+	}
+
+	return c, nil
+}
+
+// Index returns the position of the date in the calendar.
+func (c *Calendar) Index(date time.Time) (int, bool) {
+	i, ok := c.index[date.Format(c.dateFormat)]
+	return i, ok
+}
+
+// Return returns the return of the asset on day i since the previous day it
+// traded, or false when it did not trade on day i or has no earlier price.
+func (c *Calendar) Return(name string, i int) (float64, bool) {
+	closes, traded := c.Closings[name], c.Traded[name]
+	if i < 1 || i >= len(closes) || !traded[i] || closes[i-1] == 0 {
+		return 0, false
+	}
+	// The previous day's close is the price of the previous day it traded, carried forward.
+	return closes[i]/closes[i-1] - 1, true
+}
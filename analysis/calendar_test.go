@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+)
+
+// testRepository serves the snapshots of each asset from memory.
+type testRepository struct {
+	asset.Repository
+	snapshots map[string][]*asset.Snapshot
+}
+
+func (r *testRepository) Get(name string) (<-chan *asset.Snapshot, error) {
+	return helper.SliceToChan(r.snapshots[name]), nil
+}
+
+func TestNewCalendar(t *testing.T) {
+	days := newTestSnapshots(10, 11, 12, 13)
+	// B has no price on the first and third days.
+	b := newTestSnapshots(0, 20, 0, 22)
+	r := &testRepository{snapshots: map[string][]*asset.Snapshot{
+		"A": days,
+		"B": {b[3], b[1]},
+	}}
+
+	c, err := NewCalendar(r, []string{"A", "B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Dates) != 4 || !c.Dates[0].Equal(testDate) {
+		t.Fatalf("actual dates %v", c.Dates)
+	}
+	if expected := []float64{0, 20, 20, 22}; !equalFloats(c.Closings["B"], expected) {
+		t.Fatalf("actual closings %v expected %v", c.Closings["B"], expected)
+	}
+	if i, ok := c.Index(days[2].Date); !ok || i != 2 {
+		t.Fatalf("actual index %d, %v expected 2", i, ok)
+	}
+	if _, ok := c.Index(testDate.AddDate(0, 0, 9)); ok {
+		t.Fatal("expected no index for a date outside the calendar")
+	}
+
+	tests := []struct {
+		name   string
+		day    int
+		value  float64
+		traded bool
+	}{
+		{"A", 0, 0, false},
+		{"A", 1, 0.1, true},
+		{"B", 1, 0, false}, // No earlier price
+		{"B", 2, 0, false}, // Price carried forward
+		{"B", 3, 0.1, true},
+		{"B", 4, 0, false},
+	}
+	for _, test := range tests {
+		value, ok := c.Return(test.name, test.day)
+		if ok != test.traded || !equalFloats([]float64{value}, []float64{test.value}) {
+			t.Fatalf("%s on day %d: actual %g, %v expected %g, %v", test.name, test.day, value, ok, test.value, test.traded)
+		}
+	}
+}
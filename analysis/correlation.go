@@ -0,0 +1,266 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultCorrelationWindow is the default number of days over which returns are correlated.
+	DefaultCorrelationWindow = 60
+
+	// DefaultCorrelationThreshold is the default correlation at or above which assets move together.
+	DefaultCorrelationThreshold = 0.7
+)
+
+// Correlation returns the Pearson correlation between the returns of two
+// assets over the window of days ending at day end. Only the days on which
+// both assets traded are used, each return running from the previous such
+// day, so that prices carried forward over days an asset did not trade do not
+// enter as zero returns. It returns false when fewer than half the days in
+// the window have returns for both assets.
+func (c *Calendar) Correlation(a, b string, end, window int) (float64, bool) {
+	start := end - window + 1
+	if start < 1 || end >= len(c.Dates) {
+		return 0, false
+	}
+
+	closesA, closesB := c.Closings[a], c.Closings[b]
+	tradedA, tradedB := c.Traded[a], c.Traded[b]
+	if len(closesA) != len(c.Dates) || len(closesB) != len(c.Dates) {
+		return 0, false
+	}
+
+	// Find the last day both traded before the window, from which the first return runs.
+	previous := -1
+	for i := start - 1; i >= 0; i-- {
+		if tradedA[i] && tradedB[i] {
+			previous = i
+			break
+		}
+	}
+
+	ra := make([]float64, 0, window)
+	rb := make([]float64, 0, window)
+	for i := start; i <= end; i++ {
+		if !tradedA[i] || !tradedB[i] {
+			continue
+		}
+		if previous >= 0 && closesA[previous] != 0 && closesB[previous] != 0 {
+			ra = append(ra, closesA[i]/closesA[previous]-1)
+			rb = append(rb, closesB[i]/closesB[previous]-1)
+		}
+		previous = i
+	}
+	if len(ra) < max(2, (window+1)/2) {
+		return 0, false
+	}
+
+	sa, sb := stddev(ra), stddev(rb)
+	if sa == 0 || sb == 0 {
+		return 0, false
+	}
+	return covariance(ra, rb) / (sa * sb), true
+}
+
+// RollingCorrelation returns the correlation between the returns of two assets
+// over the window of days ending at each day of the calendar, NaN where it is
+// not available.
+func (c *Calendar) RollingCorrelation(a, b string, window int) []float64 {
+	values := make([]float64, len(c.Dates))
+	for end := range c.Dates {
+		v, ok := c.Correlation(a, b, end, window)
+		if !ok {
+			v = math.NaN()
+		}
+		values[end] = v
+	}
+	return values
+}
+
+// CorrelationMatrix holds the correlations between pairs of assets on a date.
+// Values is NaN where a correlation is not available.
+type CorrelationMatrix struct {
+	Date   time.Time
+	Names  []string
+	Values [][]float64
+}
+
+// CorrelationMatrix returns the correlations between all the named assets
+// over the window of days ending at day end.
+func (c *Calendar) CorrelationMatrix(names []string, end, window int) *CorrelationMatrix {
+	m := &CorrelationMatrix{
+		Names:  names,
+		Values: make([][]float64, len(names)),
+	}
+	if end >= 0 && end < len(c.Dates) {
+		m.Date = c.Dates[end]
+	}
+	for i := range names {
+		m.Values[i] = make([]float64, len(names))
+	}
+	for i := range names {
+		m.Values[i][i] = 1
+		for j := i + 1; j < len(names); j++ {
+			v, ok := c.Correlation(names[i], names[j], end, window)
+			if !ok {
+				v = math.NaN()
+			}
+			m.Values[i][j] = v
+			m.Values[j][i] = v
+		}
+	}
+	return m
+}
+
+// RollingCorrelationMatrix returns the correlation matrices of the named
+// assets over the window of days ending at every step days back from the last
+// day of the calendar to day from, oldest first.
+func (c *Calendar) RollingCorrelationMatrix(names []string, from, window, step int) []*CorrelationMatrix {
+	step = max(1, step)
+	var matrices []*CorrelationMatrix
+	for end := len(c.Dates) - 1; end >= max(0, from); end -= step {
+		matrices = append(matrices, c.CorrelationMatrix(names, end, window))
+	}
+	for i, j := 0, len(matrices)-1; i < j; i, j = i+1, j-1 {
+		matrices[i], matrices[j] = matrices[j], matrices[i]
+	}
+	return matrices
+}
+
+// Average returns the average of the available correlations between
+// different assets, or NaN when there are none. A falling average means the
+// assets are becoming more diversified.
+func (m *CorrelationMatrix) Average() float64 {
+	sum, n := 0.0, 0
+	for i := range m.Names {
+		for j := i + 1; j < len(m.Names); j++ {
+			if v := m.Values[i][j]; !math.IsNaN(v) {
+				sum += v
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
+
+// Reorder returns the matrix with its assets in the given order.
+func (m *CorrelationMatrix) Reorder(names []string) *CorrelationMatrix {
+	position := make(map[string]int, len(m.Names))
+	for i, name := range m.Names {
+		position[name] = i
+	}
+	r := &CorrelationMatrix{
+		Date:   m.Date,
+		Names:  names,
+		Values: make([][]float64, len(names)),
+	}
+	for i, a := range names {
+		r.Values[i] = make([]float64, len(names))
+		for j, b := range names {
+			r.Values[i][j] = m.Values[position[a]][position[b]]
+		}
+	}
+	return r
+}
+
+// Cluster groups the assets by average linkage hierarchical clustering,
+// merging clusters while their average correlation is at or above the threshold.
+// Unavailable correlations count as zero. The largest clusters come first.
+func (m *CorrelationMatrix) Cluster(threshold float64) [][]string {
+	clusters := make([][]int, len(m.Names))
+	for i := range clusters {
+		clusters[i] = []int{i}
+	}
+
+	linkage := func(a, b []int) float64 {
+		sum := 0.0
+		for _, i := range a {
+			for _, j := range b {
+				if v := m.Values[i][j]; !math.IsNaN(v) {
+					sum += v
+				}
+			}
+		}
+		return sum / float64(len(a)*len(b))
+	}
+
+	for len(clusters) > 1 {
+		best, bi, bj := math.Inf(-1), -1, -1
+		for i := range clusters {
+			for j := i + 1; j < len(clusters); j++ {
+				if l := linkage(clusters[i], clusters[j]); l > best {
+					best, bi, bj = l, i, j
+				}
+			}
+		}
+		if best < threshold {
+			break
+		}
+		clusters[bi] = append(clusters[bi], clusters[bj]...)
+		clusters = append(clusters[:bj], clusters[bj+1:]...)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i]) > len(clusters[j])
+	})
+
+	result := make([][]string, len(clusters))
+	for i, cluster := range clusters {
+		for _, member := range cluster {
+			result[i] = append(result[i], m.Names[member])
+		}
+	}
+	return result
+}
+
+// CorrelatedPair is a pair of assets whose returns are correlated.
+type CorrelatedPair struct {
+	A, B        string
+	Correlation float64
+}
+
+// CorrelatedBuys are the highly correlated assets signalling Buy on the same day.
+type CorrelatedBuys struct {
+	Date  time.Time
+	Pairs []CorrelatedPair
+}
+
+// CorrelatedBuys flags the days on which Buy signals fall on assets whose
+// returns over the preceding window are correlated at or above the threshold.
+// buys holds, for each asset, whether it was signalled Buy on each calendar day.
+func (c *Calendar) CorrelatedBuys(buys map[string][]bool, window int, threshold float64) []CorrelatedBuys {
+	names := make([]string, 0, len(buys))
+	for name := range buys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []CorrelatedBuys
+	for i := range c.Dates {
+		var buying []string
+		for _, name := range names {
+			if i < len(buys[name]) && buys[name][i] {
+				buying = append(buying, name)
+			}
+		}
+
+		var pairs []CorrelatedPair
+		for a := 0; a < len(buying); a++ {
+			for b := a + 1; b < len(buying); b++ {
+				v, ok := c.Correlation(buying[a], buying[b], i, window)
+				if ok && v >= threshold {
+					pairs = append(pairs, CorrelatedPair{A: buying[a], B: buying[b], Correlation: v})
+				}
+			}
+		}
+		if len(pairs) > 0 {
+			result = append(result, CorrelatedBuys{Date: c.Dates[i], Pairs: pairs})
+		}
+	}
+	return result
+}
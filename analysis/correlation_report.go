@@ -0,0 +1,106 @@
+package analysis
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"time"
+)
+
+// CorrelationReport is the content of the correlation HTML page.
+type CorrelationReport struct {
+	Date      time.Time
+	Window    int
+	Threshold float64
+	Matrix    *CorrelationMatrix
+	Rolling   []*CorrelationMatrix // Earlier matrices, oldest first, in the order of Matrix
+	Clusters  [][]string
+	Buys      []CorrelatedBuys
+}
+
+// correlationTemplate renders a correlation report as a heatmap followed by
+// the average correlation over time, the clusters and the days with
+// correlated Buy signals.
+var correlationTemplate = template.Must(template.New("correlation").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format(time.DateOnly) },
+	"value": func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return fmt.Sprintf("%.2f", v)
+	},
+	"color": func(v float64) template.CSS {
+		switch {
+		case math.IsNaN(v):
+			return "background: #eee"
+		case v >= 0:
+			return template.CSS(fmt.Sprintf("background: rgba(214, 39, 40, %.2f)", v))
+		}
+		return template.CSS(fmt.Sprintf("background: rgba(31, 119, 180, %.2f)", -v))
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Correlation {{ date .Date }}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 4px; font-size: 11px; }
+.heatmap td { text-align: center; min-width: 2.5em; }
+.heatmap th.col { writing-mode: vertical-rl; transform: rotate(180deg); }
+</style>
+</head>
+<body>
+<h1>Correlation of daily returns over {{ .Window }} days to {{ date .Date }}</h1>
+{{ template "heatmap" .Matrix }}
+
+<h2>Average correlation over time</h2>
+<table>
+<tr><th>Date</th><th>Average</th></tr>
+{{- range .Rolling }}
+<tr><td>{{ date .Date }}</td><td>{{ value .Average }}</td></tr>
+{{- end }}
+</table>
+{{- range .Rolling }}
+<details><summary>{{ date .Date }}</summary>
+{{ template "heatmap" . }}
+</details>
+{{- end }}
+
+<h2>Clusters with average correlation of at least {{ printf "%.2f" .Threshold }}</h2>
+<ol>
+{{- range .Clusters }}{{ if gt (len .) 1 }}
+<li>{{ range $i, $name := . }}{{ if $i }}, {{ end }}{{ $name }}{{ end }}</li>
+{{- end }}{{ end }}
+</ol>
+
+<h2>Days with Buy signals on correlated assets</h2>
+<table>
+<tr><th>Date</th><th>Assets</th><th>Correlation</th></tr>
+{{- range .Buys }}{{ $date := .Date }}
+{{- range .Pairs }}
+<tr><td>{{ date $date }}</td><td>{{ .A }} &amp; {{ .B }}</td><td>{{ printf "%.2f" .Correlation }}</td></tr>
+{{- end }}
+{{- end }}
+</table>
+</body>
+</html>
+{{ define "heatmap" }}<table class="heatmap">
+<tr><th></th>{{ range .Names }}<th class="col">{{ . }}</th>{{ end }}</tr>
+{{- range $i, $name := .Names }}
+<tr><th>{{ $name }}</th>{{ range index $.Values $i }}<td style="{{ color . }}">{{ value . }}</td>{{ end }}</tr>
+{{- end }}
+</table>{{ end }}`))
+
+// WriteToFile writes the correlation report as an HTML page.
+func (r *CorrelationReport) WriteToFile(fileName string) error {
+	fd, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	return correlationTemplate.Execute(fd, r)
+}
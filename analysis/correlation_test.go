@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newTestCalendar returns a calendar of the closing prices, traded on every
+// day they are not zero.
+func newTestCalendar(closings map[string][]float64) *Calendar {
+	c := &Calendar{
+		Closings: closings,
+		Traded:   make(map[string][]bool, len(closings)),
+	}
+	for name, closes := range closings {
+		c.Dates = make([]time.Time, len(closes))
+		for i := range closes {
+			c.Dates[i] = testDate.AddDate(0, 0, i)
+		}
+		c.Traded[name] = make([]bool, len(closes))
+		for i, close := range closes {
+			c.Traded[name][i] = close != 0
+		}
+	}
+	return c
+}
+
+func TestCorrelation(t *testing.T) {
+	c := newTestCalendar(map[string][]float64{
+		"A":    {10, 11, 10, 12, 11},
+		"Same": {20, 22, 20, 24, 22},
+		"Flat": {5, 5, 5, 5, 5},
+		"Late": {0, 0, 0, 7, 8},
+	})
+	tests := []struct {
+		b         string
+		end       int
+		window    int
+		expected  float64
+		available bool
+	}{
+		{"Same", 4, 4, 1, true},
+		{"Same", 4, 2, 1, true},
+		{"Same", 4, 5, 0, false}, // The window starts before the first return
+		{"Flat", 4, 4, 0, false}, // No variance
+		{"Late", 4, 4, 0, false}, // Too few common returns
+	}
+	for _, test := range tests {
+		v, ok := c.Correlation("A", test.b, test.end, test.window)
+		if ok != test.available || !equalFloats([]float64{v}, []float64{test.expected}) {
+			t.Fatalf("A and %s: actual %g, %v expected %g, %v", test.b, v, ok, test.expected, test.available)
+		}
+	}
+
+	rolling := c.RollingCorrelation("A", "Same", 2)
+	if !math.IsNaN(rolling[1]) || !equalFloats(rolling[2:], []float64{1, 1, 1}) {
+		t.Fatalf("actual rolling correlation %v", rolling)
+	}
+
+	matrices := c.RollingCorrelationMatrix([]string{"A", "Same"}, 0, 2, 2)
+	if len(matrices) != 3 || !matrices[0].Date.Equal(c.Dates[0]) || !matrices[2].Date.Equal(c.Dates[4]) {
+		t.Fatalf("actual %d matrices", len(matrices))
+	}
+}
+
+func TestCorrelationMatrix(t *testing.T) {
+	m := &CorrelationMatrix{
+		Names: []string{"A", "B", "C", "D"},
+		Values: [][]float64{
+			{1, 0.9, 0.1, math.NaN()},
+			{0.9, 1, 0.2, 0.3},
+			{0.1, 0.2, 1, 0.8},
+			{math.NaN(), 0.3, 0.8, 1},
+		},
+	}
+	if expected := (0.9 + 0.1 + 0.2 + 0.3 + 0.8) / 5; !equalFloats([]float64{m.Average()}, []float64{expected}) {
+		t.Fatalf("actual average %g expected %g", m.Average(), expected)
+	}
+
+	r := m.Reorder([]string{"C", "A", "B", "D"})
+	if r.Values[0][1] != 0.1 || r.Values[1][2] != 0.9 || r.Values[0][0] != 1 {
+		t.Fatalf("actual reordered values %v", r.Values)
+	}
+
+	tests := []struct {
+		threshold float64
+		expected  [][]string
+	}{
+		{0.85, [][]string{{"A", "B"}, {"C"}, {"D"}}},
+		{0.7, [][]string{{"A", "B"}, {"C", "D"}}},
+		{0, [][]string{{"A", "B", "C", "D"}}},
+	}
+	for _, test := range tests {
+		actual := m.Cluster(test.threshold)
+		if !slices.EqualFunc(actual, test.expected, slices.Equal) {
+			t.Fatalf("at %g: actual %v expected %v", test.threshold, actual, test.expected)
+		}
+	}
+}
+
+func TestCorrelatedBuys(t *testing.T) {
+	c := newTestCalendar(map[string][]float64{
+		"A":    {10, 11, 10, 12, 11},
+		"Same": {20, 22, 20, 24, 22},
+		"Flat": {5, 5, 5, 5, 5},
+	})
+	buys := map[string][]bool{
+		"A":    {false, false, true, false, true},
+		"Same": {false, false, false, false, true},
+		"Flat": {false, false, true, false, true},
+	}
+
+	actual := c.CorrelatedBuys(buys, 2, 0.7)
+	if len(actual) != 1 || !actual[0].Date.Equal(c.Dates[4]) {
+		t.Fatalf("actual %+v expected one day of correlated buys", actual)
+	}
+	if pairs := actual[0].Pairs; len(pairs) != 1 || pairs[0].A != "A" || pairs[0].B != "Same" {
+		t.Fatalf("actual pairs %+v expected A and Same", pairs)
+	}
+}
@@ -0,0 +1,80 @@
+package analysis
+
+import (
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+)
+
+// ComputeActions runs the strategy over the snapshots and returns its actions as -1, 0 or 1.
+func ComputeActions(st strategy.Strategy, snapshots []*asset.Snapshot) []float64 {
+	return helper.ChanToSlice(helper.Map(st.Compute(helper.SliceToChan(snapshots)), func(a strategy.Action) float64 {
+		return float64(a)
+	}))
+}
+
+// StrategyReturns returns the daily returns of following the normalized actions.
+// The strategy is invested from the day after a Buy until the day after a Sell.
+func StrategyReturns(snapshots []*asset.Snapshot, actions []float64) []float64 {
+	returns := make([]float64, len(snapshots))
+	invested := false
+	for i := range snapshots {
+		if i > 0 && invested && snapshots[i-1].Close != 0 {
+			returns[i] = snapshots[i].Close/snapshots[i-1].Close - 1
+		}
+		if i < len(actions) {
+			switch {
+			case actions[i] > 0:
+				invested = true
+			case actions[i] < 0:
+				invested = false
+			}
+		}
+	}
+	return returns
+}
+
+// Trades splits the daily returns of following the normalized actions into
+// the daily returns of each completed or still open trade.
+func Trades(snapshots []*asset.Snapshot, actions []float64) [][]float64 {
+	returns := StrategyReturns(snapshots, actions)
+
+	var trades [][]float64
+	var trade []float64
+	invested := false
+	for i := range snapshots {
+		if invested && i > 0 {
+			trade = append(trade, returns[i])
+		}
+		if i < len(actions) {
+			switch {
+			case actions[i] > 0 && !invested:
+				invested = true
+				trade = nil
+			case actions[i] < 0 && invested:
+				invested = false
+				trades = append(trades, trade)
+			}
+		}
+	}
+	if invested && len(trade) > 0 {
+		trades = append(trades, trade)
+	}
+	return trades
+}
+
+// MaxDrawdown returns the largest peak to trough decline of the equity curve
+// produced by the daily returns, as a positive fraction.
+func MaxDrawdown(returns []float64) float64 {
+	equity, peak, drawdown := 1.0, 1.0, 0.0
+	for _, r := range returns {
+		equity *= 1 + r
+		if equity > peak {
+			peak = equity
+		}
+		if dd := 1 - equity/peak; dd > drawdown {
+			drawdown = dd
+		}
+	}
+	return drawdown
+}
@@ -0,0 +1,77 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cinar/indicator/v2/strategy"
+)
+
+func TestStrategyReturnsAndTrades(t *testing.T) {
+	snapshots := newTestSnapshots(10, 11, 12, 11, 10, 12)
+	tests := []struct {
+		name    string
+		actions []float64
+		returns []float64
+		trades  [][]float64
+	}{
+		{
+			"two trades, the last open",
+			[]float64{1, 0, -1, 0, 1, 0},
+			[]float64{0, 0.1, 1.0 / 11, 0, 0, 0.2},
+			[][]float64{{0.1, 1.0 / 11}, {0.2}},
+		},
+		{
+			"never invested",
+			[]float64{0, 0, -1, 0, 0, 0},
+			[]float64{0, 0, 0, 0, 0, 0},
+			nil,
+		},
+		{
+			"bought on the last day",
+			[]float64{0, 0, 0, 0, 0, 1},
+			[]float64{0, 0, 0, 0, 0, 0},
+			nil,
+		},
+		{
+			"fewer actions than days",
+			[]float64{1},
+			[]float64{0, 0.1, 1.0 / 11, -1.0 / 12, -1.0 / 11, 0.2},
+			[][]float64{{0.1, 1.0 / 11, -1.0 / 12, -1.0 / 11, 0.2}},
+		},
+	}
+	for _, test := range tests {
+		if actual := StrategyReturns(snapshots, test.actions); !equalFloats(actual, test.returns) {
+			t.Fatalf("%s: actual returns %v expected %v", test.name, actual, test.returns)
+		}
+		actual := Trades(snapshots, test.actions)
+		if !slices.EqualFunc(actual, test.trades, equalFloats) {
+			t.Fatalf("%s: actual trades %v expected %v", test.name, actual, test.trades)
+		}
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	tests := []struct {
+		returns  []float64
+		expected float64
+	}{
+		{nil, 0},
+		{[]float64{0.1, 0.2}, 0},
+		{[]float64{0.1, -0.5, 0.2}, 0.5},
+		{[]float64{-0.1, -0.1, 0.5, -0.2}, 0.2},
+	}
+	for _, test := range tests {
+		if actual := MaxDrawdown(test.returns); !equalFloats([]float64{actual}, []float64{test.expected}) {
+			t.Fatalf("%v: actual %g expected %g", test.returns, actual, test.expected)
+		}
+	}
+}
+
+func TestComputeActions(t *testing.T) {
+	st := fixedStrategy{strategy.Buy, strategy.Hold, strategy.Sell}
+	actual := ComputeActions(st, newTestSnapshots(1, 2, 3, 4))
+	if expected := []float64{1, 0, -1, 0}; !slices.Equal(actual, expected) {
+		t.Fatalf("actual %v expected %v", actual, expected)
+	}
+}
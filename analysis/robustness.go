@@ -0,0 +1,229 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/strategy"
+)
+
+const (
+	// DefaultRobustnessIterations is the default number of resampled paths.
+	DefaultRobustnessIterations = 1000
+
+	// DefaultRobustnessBlockSize is the default number of days in a bootstrap block.
+	DefaultRobustnessBlockSize = 20
+
+	// DefaultRobustnessNoise is the default standard deviation of the relative price noise.
+	DefaultRobustnessNoise = 0.01
+
+	// DefaultRobustnessConfidence is the default confidence level of the reported intervals.
+	DefaultRobustnessConfidence = 0.90
+)
+
+// ErrInvalidRobustness is returned when a robustness test has parameters it cannot run with.
+var ErrInvalidRobustness = errors.New("invalid robustness test")
+
+// Robustness tests how dependent the results of a strategy are on the
+// single historical path it was backtested on.
+type Robustness struct {
+	// Strategy is the strategy under test.
+	Strategy strategy.Strategy
+
+	// Iterations is the number of resampled paths for each method.
+	Iterations int
+
+	// BlockSize is the number of consecutive days in each bootstrap block.
+	BlockSize int
+
+	// Noise is the standard deviation of the relative noise added to prices.
+	Noise float64
+
+	// Confidence is the confidence level of the reported intervals, such as 0.9.
+	// It must be strictly between 0 and 1.
+	Confidence float64
+
+	// Seed seeds the random number generator so that runs are reproducible.
+	Seed int64
+}
+
+// Interval is a confidence interval around the median of a distribution.
+type Interval struct {
+	Low    float64
+	Median float64
+	High   float64
+}
+
+// RobustnessResult holds the distribution of returns and drawdowns of one resampling method.
+type RobustnessResult struct {
+	Method    string
+	Returns   []float64
+	Drawdowns []float64
+	Return    Interval
+	Drawdown  Interval
+}
+
+// NewRobustness creates a robustness test for the strategy with default parameters.
+func NewRobustness(st strategy.Strategy) *Robustness {
+	return NewRobustnessWith(st, DefaultRobustnessIterations, 1)
+}
+
+// NewRobustnessWith creates a robustness test for the strategy with the given iterations and seed.
+func NewRobustnessWith(st strategy.Strategy, iterations int, seed int64) *Robustness {
+	return &Robustness{
+		Strategy:   st,
+		Iterations: iterations,
+		BlockSize:  DefaultRobustnessBlockSize,
+		Noise:      DefaultRobustnessNoise,
+		Confidence: DefaultRobustnessConfidence,
+		Seed:       seed,
+	}
+}
+
+// Validate returns an error wrapping ErrInvalidRobustness if the parameters
+// cannot be run with.
+func (r *Robustness) Validate() error {
+	if r.Iterations < 1 {
+		return fmt.Errorf("%w: iterations %d is not positive", ErrInvalidRobustness, r.Iterations)
+	}
+	if r.BlockSize < 1 {
+		return fmt.Errorf("%w: block size %d is not positive", ErrInvalidRobustness, r.BlockSize)
+	}
+	if r.Noise < 0 {
+		return fmt.Errorf("%w: noise %g is negative", ErrInvalidRobustness, r.Noise)
+	}
+	if !(r.Confidence > 0 && r.Confidence < 1) {
+		return fmt.Errorf("%w: confidence %g is not between 0 and 1", ErrInvalidRobustness, r.Confidence)
+	}
+	return nil
+}
+
+// Run validates the parameters and applies every resampling method to the snapshots.
+func (r *Robustness) Run(snapshots []*asset.Snapshot) ([]RobustnessResult, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	actions := ComputeActions(r.Strategy, snapshots)
+
+	trades, err := r.ResampleTrades(snapshots, actions)
+	if err != nil {
+		return nil, err
+	}
+	bootstrap, err := r.BootstrapReturns(snapshots, actions)
+	if err != nil {
+		return nil, err
+	}
+	noise, err := r.PerturbPrices(snapshots)
+	if err != nil {
+		return nil, err
+	}
+	return []RobustnessResult{trades, bootstrap, noise}, nil
+}
+
+// ResampleTrades draws the strategy's trades with replacement to build alternative trade sequences.
+// It returns an error if the parameters are not valid.
+func (r *Robustness) ResampleTrades(snapshots []*asset.Snapshot, actions []float64) (RobustnessResult, error) {
+	if err := r.Validate(); err != nil {
+		return RobustnessResult{}, err
+	}
+	rng := rand.New(rand.NewSource(r.Seed))
+	trades := Trades(snapshots, actions)
+
+	return r.result("Trade Resampling", func() []float64 {
+		var path []float64
+		for range trades {
+			path = append(path, trades[rng.Intn(len(trades))]...)
+		}
+		return path
+	}), nil
+}
+
+// BootstrapReturns rebuilds the strategy's daily returns from randomly chosen blocks of consecutive days.
+// It returns an error if the parameters are not valid.
+func (r *Robustness) BootstrapReturns(snapshots []*asset.Snapshot, actions []float64) (RobustnessResult, error) {
+	if err := r.Validate(); err != nil {
+		return RobustnessResult{}, err
+	}
+	rng := rand.New(rand.NewSource(r.Seed))
+	returns := StrategyReturns(snapshots, actions)
+	blockSize := max(1, min(r.BlockSize, len(returns)))
+
+	return r.result("Block Bootstrap", func() []float64 {
+		path := make([]float64, 0, len(returns))
+		for len(returns) > 0 && len(path) < len(returns) {
+			start := rng.Intn(len(returns) - blockSize + 1)
+			path = append(path, returns[start:start+blockSize]...)
+		}
+		return path[:len(returns)]
+	}), nil
+}
+
+// PerturbPrices reruns the strategy on prices with random relative noise added.
+// It returns an error if the parameters are not valid.
+func (r *Robustness) PerturbPrices(snapshots []*asset.Snapshot) (RobustnessResult, error) {
+	if err := r.Validate(); err != nil {
+		return RobustnessResult{}, err
+	}
+	rng := rand.New(rand.NewSource(r.Seed))
+
+	return r.result("Price Noise", func() []float64 {
+		noisy := make([]*asset.Snapshot, len(snapshots))
+		for i, s := range snapshots {
+			factor := 1 + rng.NormFloat64()*r.Noise
+			noisy[i] = &asset.Snapshot{
+				Date:   s.Date,
+				Open:   s.Open * factor,
+				High:   s.High * factor,
+				Low:    s.Low * factor,
+				Close:  s.Close * factor,
+				Volume: s.Volume,
+			}
+		}
+		return StrategyReturns(noisy, ComputeActions(r.Strategy, noisy))
+	}), nil
+}
+
+// result collects the return and drawdown of each path produced by the sampler.
+// The parameters must be valid.
+func (r *Robustness) result(method string, sample func() []float64) RobustnessResult {
+	res := RobustnessResult{
+		Method:    method,
+		Returns:   make([]float64, r.Iterations),
+		Drawdowns: make([]float64, r.Iterations),
+	}
+	for i := 0; i < r.Iterations; i++ {
+		path := sample()
+		res.Returns[i] = compound(path)
+		res.Drawdowns[i] = MaxDrawdown(path)
+	}
+	res.Return = confidenceInterval(res.Returns, r.Confidence)
+	res.Drawdown = confidenceInterval(res.Drawdowns, r.Confidence)
+	return res
+}
+
+// confidenceInterval returns the central interval of the values at the given confidence level.
+func confidenceInterval(values []float64, confidence float64) Interval {
+	if len(values) == 0 {
+		return Interval{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	tail := (1 - confidence) / 2
+	return Interval{
+		Low:    percentile(sorted, tail),
+		Median: percentile(sorted, 0.5),
+		High:   percentile(sorted, 1-tail),
+	}
+}
+
+// percentile returns the linearly interpolated p-th percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	pos := p * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
@@ -0,0 +1,144 @@
+package analysis
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+)
+
+// fixedStrategy takes the same actions whatever the prices.
+type fixedStrategy []strategy.Action
+
+func (s fixedStrategy) Name() string { return "Fixed" }
+
+func (s fixedStrategy) Compute(c <-chan *asset.Snapshot) <-chan strategy.Action {
+	i := 0
+	return helper.Map(c, func(*asset.Snapshot) strategy.Action {
+		action := strategy.Hold
+		if i < len(s) {
+			action = s[i]
+		}
+		i++
+		return action
+	})
+}
+
+func (s fixedStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
+	return helper.NewReport(s.Name(), asset.SnapshotsAsDates(c))
+}
+
+func TestRobustnessValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(r *Robustness)
+		valid  bool
+	}{
+		{"defaults", func(r *Robustness) {}, true},
+		{"no noise", func(r *Robustness) { r.Noise = 0 }, true},
+		{"no iterations", func(r *Robustness) { r.Iterations = 0 }, false},
+		{"no block", func(r *Robustness) { r.BlockSize = 0 }, false},
+		{"negative noise", func(r *Robustness) { r.Noise = -0.01 }, false},
+		{"zero confidence", func(r *Robustness) { r.Confidence = 0 }, false},
+		{"full confidence", func(r *Robustness) { r.Confidence = 1 }, false},
+		{"confidence as a percentage", func(r *Robustness) { r.Confidence = 90 }, false},
+		{"NaN confidence", func(r *Robustness) { r.Confidence = math.NaN() }, false},
+	}
+
+	snapshots := newTestSnapshots(10, 11, 12, 11)
+	actions := []float64{1, 0, -1, 0}
+	for _, test := range tests {
+		r := NewRobustnessWith(fixedStrategy{strategy.Buy}, 10, 1)
+		test.change(r)
+
+		err := r.Validate()
+		if (err == nil) != test.valid || (err != nil && !errors.Is(err, ErrInvalidRobustness)) {
+			t.Fatalf("%s: actual error %v", test.name, err)
+		}
+
+		// Each method checks the parameters rather than panicking.
+		_, err1 := r.ResampleTrades(snapshots, actions)
+		_, err2 := r.BootstrapReturns(snapshots, actions)
+		_, err3 := r.PerturbPrices(snapshots)
+		_, err4 := r.Run(snapshots)
+		for _, err := range []error{err1, err2, err3, err4} {
+			if (err == nil) != test.valid {
+				t.Fatalf("%s: actual error %v", test.name, err)
+			}
+		}
+	}
+}
+
+func TestRobustnessRun(t *testing.T) {
+	snapshots := newTestSnapshots(10, 11, 12.1, 11, 10, 12)
+	r := NewRobustnessWith(fixedStrategy{strategy.Buy, strategy.Hold, strategy.Sell}, 50, 1)
+	r.BlockSize = len(snapshots)
+	r.Noise = 0
+
+	results, err := r.Run(snapshots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	methods := []string{"Trade Resampling", "Block Bootstrap", "Price Noise"}
+	if len(results) != len(methods) {
+		t.Fatalf("actual %d results expected %d", len(results), len(methods))
+	}
+
+	// With a single trade, a single block and no noise, every path is the
+	// historical one.
+	for i, result := range results {
+		if result.Method != methods[i] || len(result.Returns) != r.Iterations {
+			t.Fatalf("actual result %s with %d returns", result.Method, len(result.Returns))
+		}
+		expected := Interval{Low: 0.21, Median: 0.21, High: 0.21}
+		if !equalFloats([]float64{result.Return.Low, result.Return.Median, result.Return.High},
+			[]float64{expected.Low, expected.Median, expected.High}) {
+			t.Fatalf("%s: actual return %+v expected %+v", result.Method, result.Return, expected)
+		}
+		if result.Drawdown != (Interval{}) {
+			t.Fatalf("%s: actual drawdown %+v expected none", result.Method, result.Drawdown)
+		}
+	}
+}
+
+func TestRobustnessSeed(t *testing.T) {
+	snapshots := newTestSnapshots(10, 11, 9, 12, 10, 13, 12, 14)
+	actions := []float64{1, -1, 1, -1, 1, 0, -1, 0}
+	r := NewRobustnessWith(fixedStrategy{}, 20, 7)
+	r.BlockSize = 2
+
+	first, _ := r.BootstrapReturns(snapshots, actions)
+	second, _ := r.BootstrapReturns(snapshots, actions)
+	if !equalFloats(first.Returns, second.Returns) {
+		t.Fatalf("actual %v and %v expected the same returns for the same seed", first.Returns, second.Returns)
+	}
+
+	trades, _ := r.ResampleTrades(snapshots, actions)
+	if trades.Return.Low > trades.Return.Median || trades.Return.Median > trades.Return.High {
+		t.Fatalf("actual interval %+v out of order", trades.Return)
+	}
+}
+
+func TestConfidenceInterval(t *testing.T) {
+	tests := []struct {
+		values     []float64
+		confidence float64
+		expected   Interval
+	}{
+		{[]float64{5, 1, 4, 2, 3}, 0.5, Interval{Low: 2, Median: 3, High: 4}},
+		{[]float64{5, 1, 4, 2, 3}, 0.9, Interval{Low: 1.2, Median: 3, High: 4.8}},
+		{[]float64{1, 2}, 0.5, Interval{Low: 1.25, Median: 1.5, High: 1.75}},
+		{[]float64{7}, 0.9, Interval{Low: 7, Median: 7, High: 7}},
+		{nil, 0.9, Interval{}},
+	}
+	for _, test := range tests {
+		actual := confidenceInterval(test.values, test.confidence)
+		if !equalFloats([]float64{actual.Low, actual.Median, actual.High},
+			[]float64{test.expected.Low, test.expected.Median, test.expected.High}) {
+			t.Fatalf("%v at %g: actual %+v expected %+v", test.values, test.confidence, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package analysis
+
+import "math"
+
+// TradingDaysPerYear is used to annualise daily statistics.
+const TradingDaysPerYear = 252
+
+// mean returns the arithmetic mean of the values.
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// covariance returns the sample covariance of two equally sized series.
+func covariance(a, b []float64) float64 {
+	n := min(len(a), len(b))
+	if n < 2 {
+		return 0
+	}
+	ma, mb := mean(a[:n]), mean(b[:n])
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		sum += (a[i] - ma) * (b[i] - mb)
+	}
+	return sum / float64(n-1)
+}
+
+// variance returns the sample variance of the values.
+func variance(values []float64) float64 {
+	return covariance(values, values)
+}
+
+// stddev returns the sample standard deviation of the values.
+func stddev(values []float64) float64 {
+	return math.Sqrt(variance(values))
+}
+
+// compound returns the cumulative return of a series of simple returns.
+func compound(returns []float64) float64 {
+	growth := 1.0
+	for _, r := range returns {
+		growth *= 1 + r
+	}
+	return growth - 1
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/vextasy/strategise/domain"
+)
+
+// MemoryRepository is a repository held in memory, for trying out commands
+// and strategies without a Portfolio Performance file. It is safe for
+// concurrent use.
+type MemoryRepository struct {
+	mu         sync.RWMutex
+	securities map[string]domain.SecurityInfo
+	snapshots  map[string][]*asset.Snapshot
+	positions  map[string]float64
+}
+
+// NewMemoryRepository returns an empty repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		securities: make(map[string]domain.SecurityInfo),
+		snapshots:  make(map[string][]*asset.Snapshot),
+		positions:  make(map[string]float64),
+	}
+}
+
+// Add adds the security with its snapshots, which must be in date order,
+// replacing any security of the same name.
+func (r *MemoryRepository) Add(info domain.SecurityInfo, snapshots ...*asset.Snapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.securities[info.Name] = info
+	r.snapshots[info.Name] = snapshots
+}
+
+// SetPosition sets the number of shares held in the named security.
+func (r *MemoryRepository) SetPosition(name string, shares float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.positions[name] = shares
+}
+
+// Assets returns the names of all non-retired assets in the repository.
+func (r *MemoryRepository) Assets() ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var assets []string
+	for name, info := range r.securities {
+		if !info.Retired {
+			assets = append(assets, name)
+		}
+	}
+	sort.Strings(assets)
+	return assets, nil
+}
+
+// Securities returns the description of every security, ordered by name.
+func (r *MemoryRepository) Securities() ([]domain.SecurityInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]domain.SecurityInfo, 0, len(r.securities))
+	for _, info := range r.securities {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// Security returns the description of the named security.
+func (r *MemoryRepository) Security(name string) (domain.SecurityInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info, ok := r.securities[name]
+	if !ok {
+		return domain.SecurityInfo{}, fmt.Errorf("%w: %s", asset.ErrRepositoryAssetNotFound, name)
+	}
+	return info, nil
+}
+
+// Get returns the snapshots of the named asset.
+func (r *MemoryRepository) Get(name string) (<-chan *asset.Snapshot, error) {
+	return r.GetRange(name, time.Time{}, time.Time{})
+}
+
+// GetSince returns the snapshots of the named asset since the date.
+func (r *MemoryRepository) GetSince(name string, date time.Time) (<-chan *asset.Snapshot, error) {
+	return r.GetRange(name, date, time.Time{})
+}
+
+// GetRange returns the snapshots of the named asset from one date to another, inclusive.
+func (r *MemoryRepository) GetRange(name string, from, to time.Time) (<-chan *asset.Snapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshots, ok := r.snapshots[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", asset.ErrRepositoryAssetNotFound, name)
+	}
+	var selected []*asset.Snapshot
+	for _, snapshot := range snapshots {
+		if inRange(snapshot.Date, from, to) {
+			selected = append(selected, snapshot)
+		}
+	}
+	return helper.SliceToChan(selected), nil
+}
+
+// LastDate returns the date of the last snapshot of the named asset.
+func (r *MemoryRepository) LastDate(name string) (time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshots, ok := r.snapshots[name]
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %s", asset.ErrRepositoryAssetNotFound, name)
+	}
+	if len(snapshots) == 0 {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrEmptyAsset, name)
+	}
+	return snapshots[len(snapshots)-1].Date, nil
+}
+
+// Append adds the snapshots to the end of the named asset.
+func (r *MemoryRepository) Append(name string, snapshots <-chan *asset.Snapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.snapshots[name]; !ok {
+		return fmt.Errorf("%w: %s", asset.ErrRepositoryAssetNotFound, name)
+	}
+	for snapshot := range snapshots {
+		r.snapshots[name] = append(r.snapshots[name], snapshot)
+	}
+	return nil
+}
+
+// Positions returns the shares held in each security with a non-zero position.
+func (r *MemoryRepository) Positions() ([]domain.Position, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sortedPositions(r.positions), nil
+}
+
+// MemoryRepository must satisfy the repository interface.
+var _ domain.Repository = (*MemoryRepository)(nil)
@@ -0,0 +1,391 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/vextasy/strategise/domain"
+)
+
+// Notifier tells someone about signals that changed to BUY or SELL.
+type Notifier interface {
+	// Notify sends the notification. A notification without changes is not sent.
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Notification is the BUY and SELL signals that changed in a run.
+type Notification struct {
+	RunAt   time.Time
+	Changes []DigestEntry
+}
+
+// NewNotification returns the notification of the changes to BUY or SELL
+// among the changes of the run.
+func NewNotification(runAt time.Time, changes []domain.SignalChange) Notification {
+	n := Notification{RunAt: runAt}
+	for _, change := range changes {
+		if change.Current.Action == "BUY" || change.Current.Action == "SELL" {
+			n.Changes = append(n.Changes, newDigestEntry(change))
+		}
+	}
+	return n
+}
+
+// NotificationTemplate renders the subject and body of a notification
+// using the templates named "subject" and "body".
+type NotificationTemplate struct {
+	t *template.Template
+}
+
+// NewNotificationTemplate returns the default notification template.
+func NewNotificationTemplate() *NotificationTemplate {
+	return &NotificationTemplate{defaultNotificationTemplate}
+}
+
+// NewNotificationTemplateFromFile returns the template defined in the file.
+// The file may define "subject", "body" or both; the default is used for
+// any it leaves out.
+func NewNotificationTemplateFromFile(path string) (*NotificationTemplate, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := template.Must(defaultNotificationTemplate.Clone()).Parse(string(text))
+	if err != nil {
+		return nil, err
+	}
+	return &NotificationTemplate{t}, nil
+}
+
+// Render returns the subject and body of the notification.
+func (t *NotificationTemplate) Render(n Notification) (subject, body string, err error) {
+	var b strings.Builder
+	if err := t.t.ExecuteTemplate(&b, "subject", n); err != nil {
+		return "", "", err
+	}
+	subject = strings.TrimSpace(b.String())
+
+	b.Reset()
+	if err := t.t.ExecuteTemplate(&b, "body", n); err != nil {
+		return "", "", err
+	}
+	return subject, b.String(), nil
+}
+
+// defaultNotificationTemplate lists the changed signals in plain text.
+var defaultNotificationTemplate = template.Must(template.New("notification").Parse(`
+{{- define "subject" }}Strategise: {{ len .Changes }} signal change{{ if ne (len .Changes) 1 }}s{{ end }} on {{ .RunAt.Format "2006-01-02" }}{{ end }}
+{{- define "body" }}Signals that changed in the run of {{ .RunAt.Format "2006-01-02 15:04" }}:
+{{ range .Changes }}
+{{ .Current.Action }} {{ .Current.Asset }} at {{ printf "%.2f" .Current.Price }} on {{ .Current.Date.Format "2006-01-02" }}
+    {{ .Current.Strategy }} (was {{ .PreviousAction }}), report {{ .Report }}
+{{- end }}
+{{ end }}`))
+
+// SmtpNotifier sends notifications as plain text email.
+type SmtpNotifier struct {
+	// Addr is the host:port of the SMTP server.
+	Addr string
+
+	// Auth authenticates with the server, or is nil when it needs no authentication.
+	Auth smtp.Auth
+
+	From     string
+	To       []string
+	Template *NotificationTemplate
+
+	// Timeout limits how long sending a notification may take, or is zero for no limit.
+	Timeout time.Duration
+}
+
+// NewSmtpNotifier returns a notifier that emails the recipients through the
+// SMTP server at addr using the default template.
+func NewSmtpNotifier(addr string, auth smtp.Auth, from string, to ...string) *SmtpNotifier {
+	return &SmtpNotifier{
+		Addr:     addr,
+		Auth:     auth,
+		From:     from,
+		To:       to,
+		Template: NewNotificationTemplate(),
+		Timeout:  30 * time.Second,
+	}
+}
+
+// Notify emails the notification, giving up when ctx is done or the timeout passes.
+func (s *SmtpNotifier) Notify(ctx context.Context, n Notification) error {
+	if len(n.Changes) == 0 {
+		return nil
+	}
+	subject, body, err := s.Template.Render(n)
+	if err != nil {
+		return err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	return s.send(ctx, msg.Bytes())
+}
+
+// send delivers the message as smtp.SendMail does, over a connection that is
+// closed to further reads and writes once ctx is done.
+func (s *SmtpNotifier) send(ctx context.Context, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if s.Auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(s.Auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.From); err != nil {
+		return err
+	}
+	for _, to := range s.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// WebhookNotifier posts notifications as JSON to a URL.
+type WebhookNotifier struct {
+	URL      string
+	Client   *http.Client
+	Template *NotificationTemplate
+}
+
+// webhookPayload is the JSON posted by a WebhookNotifier. The rendered body
+// is sent as "text", which chat services such as Slack display as is.
+type webhookPayload struct {
+	Subject string          `json:"subject"`
+	Text    string          `json:"text"`
+	RunAt   time.Time       `json:"runAt"`
+	Signals []domain.Signal `json:"signals"`
+}
+
+// NewWebhookNotifier returns a notifier that posts to the URL using the default template.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:      url,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+		Template: NewNotificationTemplate(),
+	}
+}
+
+// Notify posts the notification, failing unless the response status is 2xx.
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	if len(n.Changes) == 0 {
+		return nil
+	}
+	subject, body, err := w.Template.Render(n)
+	if err != nil {
+		return err
+	}
+
+	payload := webhookPayload{Subject: subject, Text: body, RunAt: n.RunAt}
+	for _, change := range n.Changes {
+		payload.Signals = append(payload.Signals, change.Current)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s: %s", w.URL, resp.Status)
+	}
+	return nil
+}
+
+// CommandNotifier runs a local command for each notification. The rendered
+// body is written to the command's standard input and the subject is in the
+// STRATEGISE_SUBJECT environment variable.
+type CommandNotifier struct {
+	Name     string
+	Args     []string
+	Template *NotificationTemplate
+}
+
+// NewCommandNotifier returns a notifier that runs the command using the default template.
+func NewCommandNotifier(name string, args ...string) *CommandNotifier {
+	return &CommandNotifier{
+		Name:     name,
+		Args:     args,
+		Template: NewNotificationTemplate(),
+	}
+}
+
+// Notify runs the command, failing if it exits with a non-zero status.
+func (c *CommandNotifier) Notify(ctx context.Context, n Notification) error {
+	if len(n.Changes) == 0 {
+		return nil
+	}
+	subject, body, err := c.Template.Render(n)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Stdin = strings.NewReader(body)
+	cmd.Env = append(os.Environ(), "STRATEGISE_SUBJECT="+subject)
+	output, err := cmd.CombinedOutput()
+	if err != nil && len(bytes.TrimSpace(output)) > 0 {
+		return fmt.Errorf("%s: %w: %s", c.Name, err, bytes.TrimSpace(output))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.Name, err)
+	}
+	return nil
+}
+
+// DedupNotifier passes notifications on to another notifier, leaving out the
+// changes that it has already passed on. What has been sent is kept in a
+// JSON file so that alerts are not repeated across runs.
+type DedupNotifier struct {
+	notifier Notifier
+	path     string
+	mu       sync.Mutex
+}
+
+// NewDedupNotifier returns a notifier that remembers what it sent through the
+// notifier in the file at path. The file is created when the first
+// notification is sent.
+func NewDedupNotifier(notifier Notifier, path string) *DedupNotifier {
+	return &DedupNotifier{notifier: notifier, path: path}
+}
+
+// Notify sends the changes not sent before. They are only remembered as sent
+// when the notifier succeeds, so a failed notification is tried again next time.
+func (d *DedupNotifier) Notify(ctx context.Context, n Notification) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sent, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	fresh := Notification{RunAt: n.RunAt}
+	for _, change := range n.Changes {
+		if _, ok := sent[alertKey(change.Current)]; !ok {
+			fresh.Changes = append(fresh.Changes, change)
+		}
+	}
+	if len(fresh.Changes) == 0 {
+		return nil
+	}
+
+	if err := d.notifier.Notify(ctx, fresh); err != nil {
+		return err
+	}
+	for _, change := range fresh.Changes {
+		sent[alertKey(change.Current)] = n.RunAt
+	}
+	return d.save(sent)
+}
+
+// alertKey identifies an alert: the same action on the same day from the
+// same strategy for the same asset is only sent once.
+func alertKey(s domain.Signal) string {
+	return s.Asset + "|" + s.Strategy + "|" + s.Action + "|" + s.Date.Format("2006-01-02")
+}
+
+// load returns when each alert was sent.
+func (d *DedupNotifier) load() (map[string]time.Time, error) {
+	sent := make(map[string]time.Time)
+	data, err := os.ReadFile(d.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return sent, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &sent); err != nil {
+		return nil, fmt.Errorf("%s: %w", d.path, err)
+	}
+	return sent, nil
+}
+
+// save writes when each alert was sent.
+func (d *DedupNotifier) save(sent map[string]time.Time) error {
+	data, err := json.MarshalIndent(sent, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.path, data, 0644)
+}
@@ -0,0 +1,351 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vextasy/strategise/domain"
+)
+
+var testRunAt = time.Date(2024, 3, 1, 18, 30, 0, 0, time.UTC)
+
+// newTestNotification returns a notification of a change to the action for each asset.
+func newTestNotification(action string, assets ...string) Notification {
+	var changes []domain.SignalChange
+	for _, asset := range assets {
+		changes = append(changes, domain.SignalChange{
+			Previous: &domain.Signal{Asset: asset, Strategy: "Test", Action: "HOLD"},
+			Current: domain.Signal{
+				RunAt:    testRunAt,
+				Asset:    asset,
+				Strategy: "Test",
+				Date:     time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+				Action:   action,
+				Price:    101.5,
+			},
+		})
+	}
+	return NewNotification(testRunAt, changes)
+}
+
+func TestNewNotification(t *testing.T) {
+	changes := []domain.SignalChange{
+		{Current: domain.Signal{Asset: "Alpha", Action: "BUY"}},
+		{Current: domain.Signal{Asset: "Beta", Action: "HOLD"}},
+		{Current: domain.Signal{Asset: "Gamma", Action: "SELL"}},
+	}
+	n := NewNotification(testRunAt, changes)
+	if len(n.Changes) != 2 || n.Changes[0].Current.Asset != "Alpha" || n.Changes[1].Current.Asset != "Gamma" {
+		t.Fatalf("actual changes %+v expected Alpha and Gamma", n.Changes)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var payload webhookPayload
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("actual %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	w := NewWebhookNotifier(server.URL)
+	if err := w.Notify(context.Background(), newTestNotification("BUY", "Alpha", "Beta")); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Subject != "Strategise: 2 signal changes on 2024-03-01" {
+		t.Fatalf("actual subject %q", payload.Subject)
+	}
+	if !strings.Contains(payload.Text, "BUY Alpha at 101.50 on 2024-02-29") {
+		t.Fatalf("actual text %q", payload.Text)
+	}
+	if len(payload.Signals) != 2 || payload.Signals[1].Asset != "Beta" || !payload.RunAt.Equal(testRunAt) {
+		t.Fatalf("actual payload %+v", payload)
+	}
+
+	status = http.StatusInternalServerError
+	err := w.Notify(context.Background(), newTestNotification("BUY", "Alpha"))
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("actual error %v expected the 500 status", err)
+	}
+}
+
+func TestNotifiersSkipEmptyNotifications(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("empty notification sent")
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL).Notify(context.Background(), Notification{RunAt: testRunAt}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// smtpServer is a stand-in SMTP server that accepts one message.
+type smtpServer struct {
+	addr     string
+	commands chan []string
+	message  chan string
+}
+
+// newSmtpServer starts a stand-in server on a local port.
+func newSmtpServer(t *testing.T) *smtpServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	s := &smtpServer{
+		addr:     l.Addr().String(),
+		commands: make(chan []string, 1),
+		message:  make(chan string, 1),
+	}
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s.serve(conn)
+	}()
+	return s
+}
+
+// serve speaks just enough SMTP to take a message.
+func (s *smtpServer) serve(conn net.Conn) {
+	var commands []string
+	defer func() { s.commands <- commands }()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		commands = append(commands, line)
+
+		switch verb := strings.ToUpper(strings.Fields(line + " ")[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL", "RCPT":
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var message strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				message.WriteString(line)
+			}
+			s.message <- message.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Unknown command")
+		}
+	}
+}
+
+func TestSmtpNotifier(t *testing.T) {
+	server := newSmtpServer(t)
+
+	s := NewSmtpNotifier(server.addr, nil, "from@example.com", "a@example.com", "b@example.com")
+	if err := s.Notify(context.Background(), newTestNotification("SELL", "Alpha")); err != nil {
+		t.Fatal(err)
+	}
+
+	message := <-server.message
+	for _, expected := range []string{
+		"From: from@example.com\r\n",
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: Strategise: 1 signal change on 2024-03-01\r\n",
+		"SELL Alpha at 101.50 on 2024-02-29\r\n",
+	} {
+		if !strings.Contains(message, expected) {
+			t.Fatalf("actual message %q expected %q in it", message, expected)
+		}
+	}
+
+	commands := strings.Join(<-server.commands, "\n")
+	for _, expected := range []string{"MAIL FROM:<from@example.com>", "RCPT TO:<a@example.com>", "RCPT TO:<b@example.com>", "QUIT"} {
+		if !strings.Contains(commands, expected) {
+			t.Fatalf("actual commands %q expected %q in them", commands, expected)
+		}
+	}
+}
+
+func TestSmtpNotifierStalledServer(t *testing.T) {
+	// The server accepts the connection but never greets the client.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+
+	s := NewSmtpNotifier(l.Addr().String(), nil, "from@example.com", "to@example.com")
+	s.Timeout = 100 * time.Millisecond
+	start := time.Now()
+	if err := s.Notify(context.Background(), newTestNotification("BUY", "Alpha")); err == nil {
+		t.Fatal("expected a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("actual %v to give up expected about the timeout", elapsed)
+	}
+
+	// Cancelling the context gives up too.
+	s.Timeout = 0
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := s.Notify(ctx, newTestNotification("BUY", "Alpha")); err == nil {
+		t.Fatal("expected an error once the context is done")
+	}
+}
+
+func TestCommandNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notification.txt")
+	c := NewCommandNotifier("sh", "-c", `cat > "$0"; echo "$STRATEGISE_SUBJECT" >> "$0"`, path)
+	if err := c.Notify(context.Background(), newTestNotification("BUY", "Alpha")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	if !strings.Contains(text, "BUY Alpha at 101.50") || !strings.HasSuffix(text, "Strategise: 1 signal change on 2024-03-01\n") {
+		t.Fatalf("actual output %q", text)
+	}
+
+	c = NewCommandNotifier("sh", "-c", "echo broken >&2; exit 3")
+	err = c.Notify(context.Background(), newTestNotification("BUY", "Alpha"))
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("actual error %v expected the command's output", err)
+	}
+}
+
+// recordingNotifier records the notifications it is given, failing while err is set.
+type recordingNotifier struct {
+	notifications []Notification
+	err           error
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, n Notification) error {
+	r.notifications = append(r.notifications, n)
+	return r.err
+}
+
+func TestDedupNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sent.json")
+	r := &recordingNotifier{}
+	d := NewDedupNotifier(r, path)
+	ctx := context.Background()
+
+	if err := d.Notify(ctx, newTestNotification("BUY", "Alpha")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A repeat is suppressed, even by a notifier reading the same file later.
+	d = NewDedupNotifier(r, path)
+	if err := d.Notify(ctx, newTestNotification("BUY", "Alpha")); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.notifications) != 1 {
+		t.Fatalf("actual %d notifications expected the repeat suppressed", len(r.notifications))
+	}
+
+	// Only the new change is passed on, and a failure leaves it to be tried again.
+	r.err = errors.New("unavailable")
+	if err := d.Notify(ctx, newTestNotification("BUY", "Alpha", "Beta")); !errors.Is(err, r.err) {
+		t.Fatalf("actual error %v expected %v", err, r.err)
+	}
+	r.err = nil
+	if err := d.Notify(ctx, newTestNotification("BUY", "Alpha", "Beta")); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.notifications) != 3 {
+		t.Fatalf("actual %d notifications expected 3", len(r.notifications))
+	}
+	for _, n := range r.notifications[1:] {
+		if len(n.Changes) != 1 || n.Changes[0].Current.Asset != "Beta" {
+			t.Fatalf("actual changes %+v expected only Beta", n.Changes)
+		}
+	}
+
+	// A different action for the same asset is a new alert.
+	if err := d.Notify(ctx, newTestNotification("SELL", "Alpha")); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.notifications) != 4 {
+		t.Fatalf("actual %d notifications expected 4", len(r.notifications))
+	}
+}
+
+func TestNotificationTemplateFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notification.tmpl")
+	text := `{{ define "subject" }}{{ range .Changes }}{{ .Current.Action }} {{ .Current.Asset }} {{ end }}{{ end }}`
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := NewNotificationTemplateFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, body, err := tmpl.Render(newTestNotification("SELL", "Alpha", "Beta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "SELL Alpha SELL Beta" {
+		t.Fatalf("actual subject %q expected %q", subject, "SELL Alpha SELL Beta")
+	}
+	if !strings.Contains(body, "SELL Beta at 101.50") {
+		t.Fatalf("actual body %q expected the default body", body)
+	}
+
+	// The default template is left as it was.
+	if subject, _, _ := NewNotificationTemplate().Render(newTestNotification("SELL", "Alpha")); subject != "Strategise: 1 signal change on 2024-03-01" {
+		t.Fatalf("actual default subject %q", subject)
+	}
+
+	if err := os.WriteFile(path, []byte(`{{ define "subject" }}{{ end`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewNotificationTemplateFromFile(path); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
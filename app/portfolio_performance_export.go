@@ -0,0 +1,313 @@
+package app
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vextasy/strategise/domain"
+	"github.com/vextasy/strategise/internal"
+)
+
+// The note of each security holds the latest signals between these lines,
+// leaving whatever else the note says alone.
+const (
+	noteBegin = "--- Strategise signals"
+	noteEnd   = "--- end of Strategise signals ---"
+)
+
+// xmlTextEscaper escapes text for an XML element, keeping line breaks as they are.
+var xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// watchlistPrefix starts the name of the watchlist of each action.
+const watchlistPrefix = "Strategise "
+
+// ExportSignals writes the latest signal of each strategy into a Portfolio
+// Performance XML document and returns the new document. The signals are
+// listed in the note of each security, and the securities are put on the
+// watchlists "Strategise BUY", "Strategise SELL" and "Strategise HOLD":
+// BUY and SELL hold the securities any strategy recommends buying or
+// selling, and HOLD those all strategies say to hold. The rest of the
+// document is copied unchanged.
+func ExportSignals(doc []byte, signals []domain.Signal, exportedAt time.Time) ([]byte, error) {
+	d, err := scanPortfolioDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	byAsset := make(map[string][]domain.Signal)
+	for _, signal := range signals {
+		byAsset[signal.Asset] = append(byAsset[signal.Asset], signal)
+	}
+
+	var edits []documentEdit
+	watchlists := make(map[string][]*scannedSecurity)
+	for i := range d.securities {
+		security := &d.securities[i]
+		assetSignals, ok := byAsset[internal.CleanFilename(security.name)]
+		if !ok || security.nameEnd == 0 {
+			continue
+		}
+		// Only the first security of a name is exported, as with the repository.
+		delete(byAsset, internal.CleanFilename(security.name))
+
+		edits = append(edits, security.noteEdit(mergeNote(security.note, signalNote(assetSignals, exportedAt))))
+		for _, action := range watchlistActions(assetSignals) {
+			watchlists[action] = append(watchlists[action], security)
+		}
+	}
+
+	var added bytes.Buffer
+	for _, action := range []string{"BUY", "SELL", "HOLD"} {
+		list := watchlistXml(watchlistPrefix+action, watchlists[action], d)
+		if existing, ok := d.watchlists[watchlistPrefix+action]; ok {
+			edits = append(edits, documentEdit{existing.start, existing.end, list})
+		} else {
+			added.WriteString(list + "\n")
+		}
+	}
+	switch {
+	case added.Len() == 0:
+	case d.watchlistsEnd >= 0:
+		edits = append(edits, documentEdit{d.watchlistsEnd, d.watchlistsEnd, added.String()})
+	case d.emptyWatchlists.end > 0:
+		edits = append(edits, documentEdit{d.emptyWatchlists.start, d.emptyWatchlists.end, "<watchlists>" + added.String() + "</watchlists>"})
+	default:
+		edits = append(edits, documentEdit{d.clientEnd, d.clientEnd, "<watchlists>" + added.String() + "</watchlists>\n"})
+	}
+
+	return applyEdits(doc, edits), nil
+}
+
+// ExportSignalsToFile exports the signals into the Portfolio Performance XML
+// file at path and writes the result to output, which may be the same file.
+// The output is replaced in one step so that a failed export leaves it intact.
+func ExportSignalsToFile(path, output string, signals []domain.Signal, exportedAt time.Time) error {
+	doc, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	doc, err = ExportSignals(doc, signals, exportedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	tmp := output + ".tmp"
+	if err := os.WriteFile(tmp, doc, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, output)
+}
+
+// signalNote lists the signals of an asset for its note.
+func signalNote(signals []domain.Signal, exportedAt time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s ---\n", noteBegin, exportedAt.Format("2006-01-02 15:04"))
+	for _, signal := range signals {
+		fmt.Fprintf(&b, "%s %s (%s at %.2f)\n", signal.Action, signal.Strategy, signal.Date.Format("2006-01-02"), signal.Price)
+	}
+	b.WriteString(noteEnd)
+	return b.String()
+}
+
+// mergeNote replaces the signals in the note, or adds them to its end.
+func mergeNote(note, signals string) string {
+	begin := strings.Index(note, noteBegin)
+	end := strings.Index(note, noteEnd)
+	if begin >= 0 && end > begin {
+		return note[:begin] + signals + note[end+len(noteEnd):]
+	}
+	if strings.TrimSpace(note) == "" {
+		return signals
+	}
+	return strings.TrimRight(note, "\n") + "\n\n" + signals
+}
+
+// watchlistActions returns the watchlists an asset with the signals belongs on.
+func watchlistActions(signals []domain.Signal) []string {
+	var actions []string
+	seen := make(map[string]bool)
+	for _, signal := range signals {
+		seen[signal.Action] = true
+	}
+	for _, action := range []string{"BUY", "SELL"} {
+		if seen[action] {
+			actions = append(actions, action)
+		}
+	}
+	if len(actions) == 0 {
+		actions = append(actions, "HOLD")
+	}
+	return actions
+}
+
+// watchlistXml returns a watchlist of the securities.
+func watchlistXml(name string, securities []*scannedSecurity, d *portfolioDocument) string {
+	var b strings.Builder
+	b.WriteString("<watchlist>\n<name>")
+	b.WriteString(xmlTextEscaper.Replace(name))
+	b.WriteString("</name>\n<securities>\n")
+	for _, security := range securities {
+		fmt.Fprintf(&b, "<security reference=\"%s\"/>\n", d.reference(security))
+	}
+	b.WriteString("</securities>\n</watchlist>")
+	return b.String()
+}
+
+// portfolioDocument is where the parts of a Portfolio Performance XML
+// document that an export changes are found, as byte offsets.
+type portfolioDocument struct {
+	securities      []scannedSecurity
+	watchlists      map[string]span // By watchlist name
+	watchlistsEnd   int             // Offset of </watchlists>, or -1
+	emptyWatchlists span            // A self-closing <watchlists/>, if any
+	clientEnd       int             // Offset of </client>
+}
+
+// scannedSecurity is a security of the document.
+type scannedSecurity struct {
+	position int    // Position among the securities, from 1
+	id       string // XStream id attribute, if the document uses ids
+	name     string
+	note     string
+	noteSpan span // The note element, if the security has one
+	nameEnd  int  // Offset after </name>
+}
+
+// span is the bytes from start up to end.
+type span struct {
+	start, end int
+}
+
+// documentEdit replaces a span of the document with text.
+type documentEdit struct {
+	start, end int
+	text       string
+}
+
+// reference returns the XStream reference to a security from a watchlist.
+// Documents written with ids refer to the id, the others use a relative path.
+func (d *portfolioDocument) reference(security *scannedSecurity) string {
+	if security.id != "" {
+		return security.id
+	}
+	if security.position == 1 {
+		return "../../../../securities/security"
+	}
+	return fmt.Sprintf("../../../../securities/security[%d]", security.position)
+}
+
+// noteEdit sets the note of the security.
+func (s *scannedSecurity) noteEdit(note string) documentEdit {
+	var b strings.Builder
+	b.WriteString("<note>")
+	b.WriteString(xmlTextEscaper.Replace(note))
+	b.WriteString("</note>")
+	if s.noteSpan.end > 0 {
+		return documentEdit{s.noteSpan.start, s.noteSpan.end, b.String()}
+	}
+	return documentEdit{s.nameEnd, s.nameEnd, b.String()}
+}
+
+// scanPortfolioDocument finds the securities, the watchlists and the end of
+// the client in the document.
+func scanPortfolioDocument(doc []byte) (*portfolioDocument, error) {
+	d := &portfolioDocument{
+		watchlists:    make(map[string]span),
+		watchlistsEnd: -1,
+		clientEnd:     -1,
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(doc))
+
+	var path []string
+	var security *scannedSecurity
+	var watchlist span
+	var watchlistName, text string
+	for {
+		start := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		end := int(decoder.InputOffset())
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			text = ""
+			switch strings.Join(path, "/") {
+			case "client/securities/security":
+				d.securities = append(d.securities, scannedSecurity{position: len(d.securities) + 1})
+				security = &d.securities[len(d.securities)-1]
+				security.id = attrValue(t, "id")
+			case "client/securities/security/note":
+				security.noteSpan.start = start
+			case "client/watchlists":
+				d.emptyWatchlists.start = start
+			case "client/watchlists/watchlist":
+				watchlist = span{start: start}
+				watchlistName = ""
+			}
+
+		case xml.CharData:
+			text += string(t)
+
+		case xml.EndElement:
+			if len(path) == 0 {
+				return nil, errors.New("unbalanced XML")
+			}
+			switch strings.Join(path, "/") {
+			case "client/securities/security/name":
+				security.name = text
+				security.nameEnd = end
+			case "client/securities/security/note":
+				security.note = text
+				security.noteSpan.end = end
+			case "client/watchlists/watchlist/name":
+				watchlistName = text
+			case "client/watchlists/watchlist":
+				watchlist.end = end
+				d.watchlists[watchlistName] = watchlist
+			case "client/watchlists":
+				if start == end {
+					d.emptyWatchlists.end = end
+				} else {
+					d.watchlistsEnd = start
+				}
+			case "client":
+				d.clientEnd = start
+			}
+			path = path[:len(path)-1]
+		}
+	}
+
+	if d.clientEnd < 0 {
+		return nil, errors.New("not a Portfolio Performance XML document")
+	}
+	return d, nil
+}
+
+// applyEdits returns the document with the edits made.
+// The edits must not overlap.
+func applyEdits(doc []byte, edits []documentEdit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var b bytes.Buffer
+	last := 0
+	for _, edit := range edits {
+		b.Write(doc[last:edit.start])
+		b.WriteString(edit.text)
+		last = edit.end
+	}
+	b.Write(doc[last:])
+	return b.Bytes()
+}
@@ -0,0 +1,276 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vextasy/strategise/domain"
+)
+
+var testExportedAt = time.Date(2024, 3, 1, 18, 30, 0, 0, time.UTC)
+
+// newTestSignals returns the signals exported in the tests. Gamma is not in
+// any of the documents.
+func newTestSignals() []domain.Signal {
+	date := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	return []domain.Signal{
+		{Asset: "Alpha", Strategy: "S1", Date: date, Action: "BUY", Price: 101.5},
+		{Asset: "Alpha", Strategy: "S2", Date: date, Action: "HOLD", Price: 101.5},
+		{Asset: "Beta", Strategy: "S1", Date: date, Action: "HOLD", Price: 20},
+		{Asset: "Gamma", Strategy: "S1", Date: date, Action: "SELL", Price: 3},
+	}
+}
+
+// exportTests are documents and what exporting the test signals into them
+// makes of them, byte for byte.
+var exportTests = []struct {
+	name     string
+	doc      string
+	expected string
+}{
+	{
+		"path references and no watchlists",
+		`<?xml version="1.0" encoding="UTF-8"?>
+<client>
+  <version>66</version>
+  <securities>
+    <security>
+      <uuid>a</uuid>
+      <name>Alpha</name>
+      <currencyCode>GBP</currencyCode>
+    </security>
+    <security>
+      <uuid>b</uuid>
+      <name>Beta</name>
+      <note>Bought for income &amp; growth</note>
+    </security>
+  </securities>
+  <!-- keep me -->
+</client>
+`,
+		`<?xml version="1.0" encoding="UTF-8"?>
+<client>
+  <version>66</version>
+  <securities>
+    <security>
+      <uuid>a</uuid>
+      <name>Alpha</name><note>--- Strategise signals 2024-03-01 18:30 ---
+BUY S1 (2024-02-29 at 101.50)
+HOLD S2 (2024-02-29 at 101.50)
+--- end of Strategise signals ---</note>
+      <currencyCode>GBP</currencyCode>
+    </security>
+    <security>
+      <uuid>b</uuid>
+      <name>Beta</name>
+      <note>Bought for income &amp; growth
+
+--- Strategise signals 2024-03-01 18:30 ---
+HOLD S1 (2024-02-29 at 20.00)
+--- end of Strategise signals ---</note>
+    </security>
+  </securities>
+  <!-- keep me -->
+<watchlists><watchlist>
+<name>Strategise BUY</name>
+<securities>
+<security reference="../../../../securities/security"/>
+</securities>
+</watchlist>
+<watchlist>
+<name>Strategise SELL</name>
+<securities>
+</securities>
+</watchlist>
+<watchlist>
+<name>Strategise HOLD</name>
+<securities>
+<security reference="../../../../securities/security[2]"/>
+</securities>
+</watchlist>
+</watchlists>
+</client>
+`,
+	},
+	{
+		"id references and self-closing watchlists",
+		`<client id="1">
+  <securities id="2">
+    <security id="3">
+      <name>Beta</name>
+      <note>Keep this.
+
+--- Strategise signals 2024-01-01 09:00 ---
+SELL Old (2023-12-29 at 1.00)
+--- end of Strategise signals ---
+And this.</note>
+    </security>
+    <security id="4">
+      <name>Alpha</name>
+    </security>
+  </securities>
+  <watchlists/>
+</client>
+`,
+		`<client id="1">
+  <securities id="2">
+    <security id="3">
+      <name>Beta</name>
+      <note>Keep this.
+
+--- Strategise signals 2024-03-01 18:30 ---
+HOLD S1 (2024-02-29 at 20.00)
+--- end of Strategise signals ---
+And this.</note>
+    </security>
+    <security id="4">
+      <name>Alpha</name><note>--- Strategise signals 2024-03-01 18:30 ---
+BUY S1 (2024-02-29 at 101.50)
+HOLD S2 (2024-02-29 at 101.50)
+--- end of Strategise signals ---</note>
+    </security>
+  </securities>
+  <watchlists><watchlist>
+<name>Strategise BUY</name>
+<securities>
+<security reference="4"/>
+</securities>
+</watchlist>
+<watchlist>
+<name>Strategise SELL</name>
+<securities>
+</securities>
+</watchlist>
+<watchlist>
+<name>Strategise HOLD</name>
+<securities>
+<security reference="3"/>
+</securities>
+</watchlist>
+</watchlists>
+</client>
+`,
+	},
+	{
+		"existing watchlists",
+		`<client>
+  <securities>
+    <security>
+      <name>Alpha</name>
+    </security>
+    <security>
+      <name>Beta</name>
+    </security>
+  </securities>
+  <watchlists>
+    <watchlist>
+      <name>Mine</name>
+      <securities>
+        <security reference="../../../../securities/security[2]"/>
+      </securities>
+    </watchlist>
+    <watchlist>
+      <name>Strategise SELL</name>
+      <securities>
+        <security reference="../../../../securities/security[2]"/>
+      </securities>
+    </watchlist>
+  </watchlists>
+</client>
+`,
+		`<client>
+  <securities>
+    <security>
+      <name>Alpha</name><note>--- Strategise signals 2024-03-01 18:30 ---
+BUY S1 (2024-02-29 at 101.50)
+HOLD S2 (2024-02-29 at 101.50)
+--- end of Strategise signals ---</note>
+    </security>
+    <security>
+      <name>Beta</name><note>--- Strategise signals 2024-03-01 18:30 ---
+HOLD S1 (2024-02-29 at 20.00)
+--- end of Strategise signals ---</note>
+    </security>
+  </securities>
+  <watchlists>
+    <watchlist>
+      <name>Mine</name>
+      <securities>
+        <security reference="../../../../securities/security[2]"/>
+      </securities>
+    </watchlist>
+    <watchlist>
+<name>Strategise SELL</name>
+<securities>
+</securities>
+</watchlist>
+  <watchlist>
+<name>Strategise BUY</name>
+<securities>
+<security reference="../../../../securities/security"/>
+</securities>
+</watchlist>
+<watchlist>
+<name>Strategise HOLD</name>
+<securities>
+<security reference="../../../../securities/security[2]"/>
+</securities>
+</watchlist>
+</watchlists>
+</client>
+`,
+	},
+}
+
+func TestExportSignals(t *testing.T) {
+	for _, test := range exportTests {
+		actual, err := ExportSignals([]byte(test.doc), newTestSignals(), testExportedAt)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if string(actual) != test.expected {
+			t.Fatalf("%s: actual\n%s\nexpected\n%s", test.name, actual, test.expected)
+		}
+
+		// Exporting the same signals again changes nothing.
+		again, err := ExportSignals(actual, newTestSignals(), testExportedAt)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if string(again) != string(actual) {
+			t.Fatalf("%s: actual re-export\n%s\nexpected\n%s", test.name, again, actual)
+		}
+	}
+}
+
+func TestExportSignalsNotPortfolio(t *testing.T) {
+	for _, doc := range []string{"", "<portfolio/>", "<client><securities></client>"} {
+		if _, err := ExportSignals([]byte(doc), newTestSignals(), testExportedAt); err == nil {
+			t.Fatalf("%q: expected an error", doc)
+		}
+	}
+}
+
+func TestExportSignalsToFile(t *testing.T) {
+	test := exportTests[0]
+	path := filepath.Join(t.TempDir(), "portfolio.xml")
+	if err := os.WriteFile(path, []byte(test.doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ExportSignalsToFile(path, path, newTestSignals(), testExportedAt); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != test.expected {
+		t.Fatalf("actual\n%s\nexpected\n%s", actual, test.expected)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("actual %v expected the temporary file gone", err)
+	}
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/vextasy/strategise/domain"
+)
+
+// Portfolio Performance stores the number of shares scaled up by 1e6.
+const sharesScale = 1e6
+
+// portfolioTransactionElements are the elements holding a portfolio
+// transaction: in a portfolio's list, in the cross entry of a buy or sell,
+// and at either end of a transfer between portfolios.
+var portfolioTransactionElements = map[string]bool{
+	"portfolio-transaction": true,
+	"portfolioTransaction":  true,
+	"transactionFrom":       true,
+	"transactionTo":         true,
+}
+
+// sharesSign is how each type of portfolio transaction changes a position.
+var sharesSign = map[string]float64{
+	"BUY":               1,
+	"DELIVERY_INBOUND":  1,
+	"TRANSFER_IN":       1,
+	"SELL":              -1,
+	"DELIVERY_OUTBOUND": -1,
+	"TRANSFER_OUT":      -1,
+}
+
+// readPositions returns the number of shares held in each of the securities,
+// by index, summed over the portfolio transactions of the document.
+//
+// Each transaction is written out in full once, wherever it is first met,
+// and referred to elsewhere, so the document is scanned for every transaction
+// element with content rather than followed through its portfolios.
+func readPositions(doc []byte, securities []domain.Security) (map[int]float64, error) {
+	type frame struct {
+		name        string
+		transaction bool
+		text        string
+		kind        string
+		shares      float64
+		security    int
+		hasSecurity bool
+	}
+
+	positions := make(map[int]float64)
+	decoder := xml.NewDecoder(bytes.NewReader(doc))
+	var stack []*frame
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			f := &frame{name: t.Name.Local}
+			reference := attrValue(t, "reference")
+			f.transaction = portfolioTransactionElements[f.name] && reference == ""
+			if f.name == "security" && reference != "" && len(stack) > 0 && stack[len(stack)-1].transaction {
+				parent := stack[len(stack)-1]
+				parent.security, parent.hasSecurity = resolveSecurity(reference, securities)
+			}
+			stack = append(stack, f)
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+
+		case xml.EndElement:
+			if len(stack) == 0 {
+				break
+			}
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 && stack[len(stack)-1].transaction {
+				parent := stack[len(stack)-1]
+				switch f.name {
+				case "type":
+					parent.kind = strings.TrimSpace(f.text)
+				case "shares":
+					shares, err := strconv.ParseFloat(strings.TrimSpace(f.text), 64)
+					if err == nil {
+						parent.shares = shares / sharesScale
+					}
+				}
+			}
+			if f.transaction && f.hasSecurity {
+				positions[f.security] += sharesSign[f.kind] * f.shares
+			}
+		}
+	}
+	return positions, nil
+}
+
+// resolveSecurity returns the index of the security an XStream reference
+// refers to. A reference is either a relative path ending in the security's
+// position among the client's securities, or the security's id.
+func resolveSecurity(reference string, securities []domain.Security) (int, bool) {
+	i := strings.LastIndex(reference, "securities/security")
+	if i < 0 {
+		for index, security := range securities {
+			if security.ID != "" && security.ID == reference {
+				return index, true
+			}
+		}
+		return 0, false
+	}
+
+	position := strings.TrimPrefix(reference[i:], "securities/security")
+	if position == "" {
+		return 0, len(securities) > 0
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(position, "["), "]"))
+	if err != nil || n < 1 || n > len(securities) {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// attrValue returns the value of the element's attribute, or "" if it has none.
+func attrValue(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/vextasy/strategise/domain"
+)
+
+// pathPositionsDoc refers to securities by path. Alpha is bought, partly
+// sold through an account's cross entry and partly moved to another
+// portfolio; Beta is bought and sold in full. The transfer's transactions
+// are listed again by reference in the portfolios and count only once.
+const pathPositionsDoc = `<client>
+  <securities>
+    <security><name>Alpha</name></security>
+    <security><name>Beta</name></security>
+    <security><name>Gamma</name></security>
+  </securities>
+  <accounts>
+    <account>
+      <transactions>
+        <account-transaction>
+          <crossEntry class="buysell">
+            <portfolio>
+              <transactions>
+                <portfolio-transaction>
+                  <security reference="../../../../../../../../../securities/security"/>
+                  <shares>10000000</shares>
+                  <type>BUY</type>
+                </portfolio-transaction>
+                <portfolio-transaction>
+                  <security reference="../../../../../../../../../securities/security[2]"/>
+                  <shares>5000000</shares>
+                  <type>BUY</type>
+                </portfolio-transaction>
+              </transactions>
+            </portfolio>
+            <portfolioTransaction>
+              <security reference="../../../../../../securities/security"/>
+              <shares>2500000</shares>
+              <type>SELL</type>
+            </portfolioTransaction>
+          </crossEntry>
+          <type>SELL</type>
+        </account-transaction>
+      </transactions>
+    </account>
+  </accounts>
+  <portfolios>
+    <portfolio reference="../../accounts/account/transactions/account-transaction/crossEntry/portfolio"/>
+    <portfolio>
+      <transactions>
+        <portfolio-transaction>
+          <security reference="../../../../../securities/security[2]"/>
+          <shares>5000000</shares>
+          <type>SELL</type>
+        </portfolio-transaction>
+        <portfolio-transaction reference="../../../../portfolio-transfers/portfolio-transfer/transactionTo"/>
+      </transactions>
+    </portfolio>
+  </portfolios>
+  <portfolio-transfers>
+    <portfolio-transfer>
+      <transactionFrom>
+        <security reference="../../../../securities/security"/>
+        <shares>1500000</shares>
+        <type>TRANSFER_OUT</type>
+      </transactionFrom>
+      <transactionTo>
+        <security reference="../../../../securities/security"/>
+        <shares>1500000</shares>
+        <type>TRANSFER_IN</type>
+      </transactionTo>
+    </portfolio-transfer>
+  </portfolio-transfers>
+</client>`
+
+// idPositionsDoc refers to securities by id.
+const idPositionsDoc = `<client id="1">
+  <securities id="2">
+    <security id="3"><name>Alpha</name></security>
+    <security id="4"><name>Beta</name></security>
+  </securities>
+  <portfolios id="5">
+    <portfolio id="6">
+      <transactions id="7">
+        <portfolio-transaction id="8">
+          <security reference="4"/>
+          <shares>3000000</shares>
+          <type>DELIVERY_INBOUND</type>
+        </portfolio-transaction>
+        <portfolio-transaction id="9">
+          <security reference="4"/>
+          <shares>1000000</shares>
+          <type>DELIVERY_OUTBOUND</type>
+        </portfolio-transaction>
+        <portfolio-transaction reference="8"/>
+        <portfolio-transaction id="10">
+          <security reference="99"/>
+          <shares>1000000</shares>
+          <type>BUY</type>
+        </portfolio-transaction>
+      </transactions>
+    </portfolio>
+  </portfolios>
+</client>`
+
+func TestReadPositions(t *testing.T) {
+	tests := []struct {
+		name       string
+		doc        string
+		securities []domain.Security
+		expected   map[int]float64
+	}{
+		{
+			"path references",
+			pathPositionsDoc,
+			[]domain.Security{{Name: "Alpha"}, {Name: "Beta"}, {Name: "Gamma"}},
+			map[int]float64{0: 7.5, 1: 0},
+		},
+		{
+			"id references",
+			idPositionsDoc,
+			[]domain.Security{{ID: "3", Name: "Alpha"}, {ID: "4", Name: "Beta"}},
+			map[int]float64{1: 2},
+		},
+	}
+	for _, test := range tests {
+		actual, err := readPositions([]byte(test.doc), test.securities)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !maps.Equal(actual, test.expected) {
+			t.Fatalf("%s: actual %v expected %v", test.name, actual, test.expected)
+		}
+	}
+}
+
+func TestPortfolioPerformanceRepositoryPositions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "portfolio.xml")
+	if err := os.WriteFile(path, []byte(pathPositionsDoc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewPortfolioPerformanceRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The closed position in Beta is left out.
+	positions, err := r.Positions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []domain.Position{{Security: "Alpha", Shares: 7.5}}
+	if !slices.Equal(positions, expected) {
+		t.Fatalf("actual positions %v expected %v", positions, expected)
+	}
+}
+
+func TestResolveSecurity(t *testing.T) {
+	securities := []domain.Security{{ID: "3"}, {ID: "4"}}
+	tests := []struct {
+		reference string
+		index     int
+		ok        bool
+	}{
+		{"../../securities/security", 0, true},
+		{"../../securities/security[2]", 1, true},
+		{"../../securities/security[3]", 0, false},
+		{"../../securities/security[x]", 0, false},
+		{"4", 1, true},
+		{"5", 0, false},
+	}
+	for _, test := range tests {
+		index, ok := resolveSecurity(test.reference, securities)
+		if index != test.index || ok != test.ok {
+			t.Fatalf("%s: actual %d, %v expected %d, %v", test.reference, index, ok, test.index, test.ok)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"sort"
 	"time"
@@ -14,21 +15,45 @@ import (
 	"github.com/vextasy/strategise/internal"
 )
 
+// ErrEmptyAsset is returned for an asset that has no prices.
+var ErrEmptyAsset = errors.New("asset has no prices")
+
+// A PortfolioError is a problem reading a Portfolio Performance file.
+// Security names the security concerned, if the problem is with one.
+type PortfolioError struct {
+	Path     string
+	Security string
+	Err      error
+}
+
+func (e *PortfolioError) Error() string {
+	if e.Security != "" {
+		return fmt.Sprintf("%s: security %s: %v", e.Path, e.Security, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *PortfolioError) Unwrap() error {
+	return e.Err
+}
+
 // portfolioPerformanceRepository stores data for each secuity in the portfolio.
 type portfolioPerformanceRepository struct {
-	Securities map[string]domain.Security
+	securities map[string]domain.Security
 
-	dateFormat string // Date format used by the indicator library
+	dates      map[string][]time.Time // Parsed dates of each security's prices
+	positions  map[string]float64     // Shares held in each security
+	dateFormat string                 // Date format used by the indicator library
 }
 
 // NewPortfolioPerformanceRepository initialises the repository from an XML file.
-func NewPortfolioPerformanceRepository(path string) (asset.Repository, error) {
+// Problems with the file are returned as a *PortfolioError.
+func NewPortfolioPerformanceRepository(path string) (domain.Repository, error) {
 
 	// Read the XML file
 	xmlFile, err := os.ReadFile(path)
 	if err != nil {
-		fmt.Println("Error reading XML file:", err)
-		return nil, err
+		return nil, &PortfolioError{Path: path, Err: err}
 	}
 
 	// Create a Client struct to unmarshal the XML data into
@@ -37,12 +62,13 @@ func NewPortfolioPerformanceRepository(path string) (asset.Repository, error) {
 	// Unmarshal the XML data into the client struct
 	err = xml.Unmarshal(xmlFile, &client)
 	if err != nil {
-		fmt.Println("Error unmarshaling XML:", err)
-		return nil, err
+		return nil, &PortfolioError{Path: path, Err: fmt.Errorf("unmarshaling XML: %w", err)}
 	}
 
 	r := &portfolioPerformanceRepository{
-		Securities: make(map[string]domain.Security),
+		securities: make(map[string]domain.Security),
+		dates:      make(map[string][]time.Time),
+		positions:  make(map[string]float64),
 	}
 
 	// Ensure that all security names are suitable for writing as a filename.
@@ -53,18 +79,7 @@ func NewPortfolioPerformanceRepository(path string) (asset.Repository, error) {
 	// r.dateFormat is the date format expected by the indicator library.
 	r.dateFormat, err = internal.GetStructTag(asset.Snapshot{}, "Date", "format")
 	if err != nil {
-		fmt.Println("Error getting struct tag for asset.Snapshot Date format:", err)
-		return nil, err
-	}
-	// Ensure that Price Date string is in the format expected by the indicator library.
-	for _, security := range client.Securities {
-		for _, price := range security.Prices {
-			_, err := time.Parse(r.dateFormat, price.Date)
-			if err != nil {
-				fmt.Println("Error parsing Price Date", price.Date, "for security", security.Name, ": ", err)
-				return nil, err
-			}
-		}
+		return nil, fmt.Errorf("getting struct tag for asset.Snapshot Date format: %w", err)
 	}
 
 	for _, security := range client.Securities {
@@ -73,20 +88,62 @@ func NewPortfolioPerformanceRepository(path string) (asset.Repository, error) {
 			return security.Prices[i].Date < security.Prices[j].Date
 		})
 
+		// Ensure that Price Date string is in the format expected by the indicator library.
+		dates := make([]time.Time, len(security.Prices))
+		for pi, price := range security.Prices {
+			dates[pi], err = time.Parse(r.dateFormat, price.Date)
+			if err != nil {
+				return nil, &PortfolioError{Path: path, Security: security.Name, Err: fmt.Errorf("parsing price date: %w", err)}
+			}
+		}
+
 		// Portfolio Performance stores the price.Value scaled up by 1e8.
 		for pi := range security.Prices {
 			security.Prices[pi].Value = security.Prices[pi].Value / 1e8
 		}
-		r.Securities[security.Name] = security
+		r.securities[security.Name] = security
+		r.dates[security.Name] = dates
+	}
+
+	positions, err := readPositions(xmlFile, client.Securities)
+	if err != nil {
+		return nil, &PortfolioError{Path: path, Err: fmt.Errorf("reading positions: %w", err)}
+	}
+	for index, shares := range positions {
+		r.positions[client.Securities[index].Name] += shares
 	}
 
 	return r, nil
 }
 
+// Securities returns the description of every security, ordered by name.
+func (r *portfolioPerformanceRepository) Securities() ([]domain.SecurityInfo, error) {
+	infos := make([]domain.SecurityInfo, 0, len(r.securities))
+	for _, security := range r.securities {
+		infos = append(infos, security.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// Security returns the description of the named security.
+func (r *portfolioPerformanceRepository) Security(name string) (domain.SecurityInfo, error) {
+	security, ok := r.securities[name]
+	if !ok {
+		return domain.SecurityInfo{}, fmt.Errorf("%w: %s", asset.ErrRepositoryAssetNotFound, name)
+	}
+	return security.Info(), nil
+}
+
+// Positions returns the shares held in each security with a non-zero position.
+func (r *portfolioPerformanceRepository) Positions() ([]domain.Position, error) {
+	return sortedPositions(r.positions), nil
+}
+
 // Assets returns the names of all non-retired assets in the repository.
 func (r *portfolioPerformanceRepository) Assets() ([]string, error) {
-	assets := make([]string, 0, len(r.Securities))
-	for _, security := range r.Securities {
+	assets := make([]string, 0, len(r.securities))
+	for _, security := range r.securities {
 		if security.IsRetired == "true" {
 			continue
 		}
@@ -103,10 +160,11 @@ func (r *portfolioPerformanceRepository) Assets() ([]string, error) {
 // so we manufacture the opening price as the previous close
 // and the high and low prices from the opening and closing prices.
 func (r *portfolioPerformanceRepository) Get(name string) (<-chan *asset.Snapshot, error) {
-	security, ok := r.Securities[name]
+	security, ok := r.securities[name]
 	if !ok {
-		return nil, asset.ErrRepositoryAssetNotFound
+		return nil, fmt.Errorf("%w: %s", asset.ErrRepositoryAssetNotFound, name)
 	}
+	dates := r.dates[name]
 	c := make(chan *asset.Snapshot)
 
 	go func() {
@@ -130,9 +188,8 @@ func (r *portfolioPerformanceRepository) Get(name string) (<-chan *asset.Snapsho
 				high = open
 				low = close
 			}
-			date, _ := time.Parse(r.dateFormat, price.Date)
 			c <- &asset.Snapshot{
-				Date:   date,
+				Date:   dates[i],
 				Open:   open,
 				High:   high,
 				Low:    low,
@@ -159,6 +216,17 @@ func (r *portfolioPerformanceRepository) GetSince(name string, date time.Time) (
 	return snapshots, nil
 }
 
+// GetRange returns the snapshots of the named asset from one date to another, inclusive.
+func (r *portfolioPerformanceRepository) GetRange(name string, from, to time.Time) (<-chan *asset.Snapshot, error) {
+	snapshots, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return helper.Filter(snapshots, func(s *asset.Snapshot) bool {
+		return inRange(s.Date, from, to)
+	}), nil
+}
+
 // LastDate returns the date of the last snapshot for the asset with the given name.
 func (r *portfolioPerformanceRepository) LastDate(name string) (time.Time, error) {
 	var last time.Time
@@ -170,7 +238,7 @@ func (r *portfolioPerformanceRepository) LastDate(name string) (time.Time, error
 
 	snapshot, ok := <-helper.Last(snapshots, 1)
 	if !ok {
-		return last, errors.New("empty asset")
+		return last, fmt.Errorf("%w: %s", ErrEmptyAsset, name)
 	}
 
 	return snapshot.Date, nil
@@ -180,15 +248,41 @@ func (r *portfolioPerformanceRepository) LastDate(name string) (time.Time, error
 func (r *portfolioPerformanceRepository) Append(name string, snapshots <-chan *asset.Snapshot) error {
 
 	for s := range snapshots {
-		security, ok := r.Securities[name]
+		security, ok := r.securities[name]
 		if !ok {
-			return asset.ErrRepositoryAssetNotFound
+			return fmt.Errorf("%w: %s", asset.ErrRepositoryAssetNotFound, name)
 		}
 		security.Prices = append(security.Prices, domain.Price{
 			Date:  s.Date.Format(r.dateFormat),
 			Value: s.Close,
 		})
-		r.Securities[name] = security
+		r.securities[name] = security
+		r.dates[name] = append(r.dates[name], s.Date)
 	}
 	return nil
 }
+
+// inRange reports whether the date is from one date to another, inclusive.
+// A zero from or to leaves that end of the range open.
+func inRange(date, from, to time.Time) bool {
+	if !from.IsZero() && date.Before(from) {
+		return false
+	}
+	if !to.IsZero() && date.After(to) {
+		return false
+	}
+	return true
+}
+
+// sortedPositions returns the non-zero positions ordered by security name.
+func sortedPositions(shares map[string]float64) []domain.Position {
+	var positions []domain.Position
+	for name, n := range shares {
+		// Leave out closed positions, allowing for rounding.
+		if math.Abs(n) >= 1/sharesScale {
+			positions = append(positions, domain.Position{Security: name, Shares: n})
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Security < positions[j].Security })
+	return positions
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/vextasy/strategise/internal"
+)
+
+// ReportIndex collects the latest action and recent outcome of every report
+// written in a run, and writes them as an index page linking to the reports.
+// It is safe for concurrent use.
+type ReportIndex struct {
+	mu     sync.Mutex
+	format string // Format of the reports linked to
+	cells  map[string]map[string]IndexCell
+}
+
+// IndexCell summarises the report of one strategy for one asset.
+type IndexCell struct {
+	Action  string  // "BUY", "SELL" or "HOLD"
+	Outcome float64 // Recent outcome of following the strategy, as a fraction, or NaN if not known
+	Report  string  // File name of the report
+}
+
+// NewReportIndex returns an empty index of the reports written in the
+// formats. It links to the HTML reports when they are written, and otherwise
+// to the reports in the first of the formats.
+func NewReportIndex(formats []string) *ReportIndex {
+	format := "html"
+	if len(formats) > 0 && !slices.Contains(formats, format) {
+		format = formats[0]
+	}
+	return &ReportIndex{
+		format: format,
+		cells:  make(map[string]map[string]IndexCell),
+	}
+}
+
+// Add records the latest action and recent outcome of the strategy's report for the asset.
+func (x *ReportIndex) Add(assetName, strategyName, action string, outcome float64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.cells[assetName] == nil {
+		x.cells[assetName] = make(map[string]IndexCell)
+	}
+	x.cells[assetName][strategyName] = IndexCell{
+		Action:  action,
+		Outcome: outcome,
+		Report:  internal.ReportFilenameFor(assetName, strategyName, x.format),
+	}
+}
+
+// Retain removes every asset from the index except the given ones.
+func (x *ReportIndex) Retain(assets []string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	keep := make(map[string]bool, len(assets))
+	for _, assetName := range assets {
+		keep[assetName] = true
+	}
+	for assetName := range x.cells {
+		if !keep[assetName] {
+			delete(x.cells, assetName)
+		}
+	}
+}
+
+// indexRow is one asset's cells, in the order of the index's strategies.
+// A nil cell has no report.
+type indexRow struct {
+	Asset string
+	Cells []*IndexCell
+}
+
+// WriteToFile writes the index as an HTML page with an asset per row and a
+// strategy per column. The page should be written to the reports' directory.
+func (x *ReportIndex) WriteToFile(path string) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var strategies []string
+	var assets []string
+	for assetName, cells := range x.cells {
+		assets = append(assets, assetName)
+		for strategyName := range cells {
+			if !seen[strategyName] {
+				seen[strategyName] = true
+				strategies = append(strategies, strategyName)
+			}
+		}
+	}
+	sort.Strings(assets)
+	sort.Strings(strategies)
+
+	rows := make([]indexRow, len(assets))
+	for i, assetName := range assets {
+		rows[i] = indexRow{Asset: assetName, Cells: make([]*IndexCell, len(strategies))}
+		for j, strategyName := range strategies {
+			if cell, ok := x.cells[assetName][strategyName]; ok {
+				rows[i].Cells[j] = &cell
+			}
+		}
+	}
+
+	fd, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	return reportIndexTemplate.Execute(fd, struct {
+		Strategies []string
+		Rows       []indexRow
+	}{strategies, rows})
+}
+
+// reportIndexTemplate lays out a report index as an HTML page. Clicking a
+// column header sorts the rows by it, and the filter box hides the rows whose
+// asset does not contain the text typed.
+var reportIndexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"percent": func(v float64) string {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "n/a"
+		}
+		return fmt.Sprintf("%+.1f%%", v*100)
+	},
+	"sortKey": func(v float64) string {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "-1e300"
+		}
+		return fmt.Sprintf("%f", v)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Strategise Reports</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: center; white-space: nowrap; }
+th { cursor: pointer; background: #f4f4f4; }
+td:first-child { text-align: left; }
+a { text-decoration: none; color: inherit; }
+.BUY { background: #d8f5d8; }
+.SELL { background: #f8d8d8; }
+.outcome { font-size: smaller; color: #555; }
+</style>
+</head>
+<body>
+<h1>Strategise Reports</h1>
+<p><input id="filter" type="search" placeholder="Filter assets" autofocus></p>
+<table id="index">
+<thead>
+<tr><th>Asset</th>{{ range .Strategies }}<th>{{ . }}</th>{{ end }}</tr>
+</thead>
+<tbody>
+{{- range .Rows }}
+<tr><td>{{ .Asset }}</td>
+{{- range .Cells }}
+{{- if . }}<td class="{{ .Action }}" data-sort="{{ sortKey .Outcome }}"><a href="{{ .Report }}">{{ .Action }}<br><span class="outcome">{{ percent .Outcome }}</span></a></td>
+{{- else }}<td data-sort="-1e300"></td>
+{{- end }}
+{{- end }}</tr>
+{{- end }}
+</tbody>
+</table>
+<script>
+const table = document.getElementById("index");
+const body = table.tBodies[0];
+
+document.getElementById("filter").addEventListener("input", e => {
+  const text = e.target.value.toLowerCase();
+  for (const row of body.rows) {
+    row.hidden = !row.cells[0].textContent.toLowerCase().includes(text);
+  }
+});
+
+table.tHead.rows[0].querySelectorAll("th").forEach((th, column) => {
+  let descending = column > 0;
+  th.addEventListener("click", () => {
+    const value = row => column === 0
+      ? row.cells[0].textContent
+      : parseFloat(row.cells[column].dataset.sort);
+    const rows = Array.from(body.rows).sort((a, b) => {
+      const x = value(a), y = value(b);
+      const order = x < y ? -1 : x > y ? 1 : 0;
+      return descending ? -order : order;
+    });
+    rows.forEach(row => body.appendChild(row));
+    descending = !descending;
+  });
+});
+</script>
+</body>
+</html>
+`))
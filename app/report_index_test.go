@@ -0,0 +1,66 @@
+package app
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReportIndex(t *testing.T) {
+	tests := []struct {
+		formats  []string
+		expected []string
+		missing  []string
+	}{
+		{
+			[]string{"csv", "html"},
+			[]string{
+				`<tr><th>Asset</th><th>X</th><th>Y</th></tr>`,
+				`<td class="BUY" data-sort="0.125000"><a href="A--X.html">BUY<br><span class="outcome">&#43;12.5%</span></a></td>`,
+				`<td class="HOLD" data-sort="-1e300"><a href="A--Y.html">HOLD<br><span class="outcome">n/a</span></a></td>`,
+				`<tr><td>B</td><td data-sort="-1e300"></td>`,
+			},
+			[]string{"<td>C</td>"},
+		},
+		{
+			// Without HTML reports the index links to ones that were written.
+			[]string{"csv", "json"},
+			[]string{`<a href="A--X.csv">`, `<a href="B--Y.csv">`},
+			[]string{".html"},
+		},
+		{
+			nil,
+			[]string{`<a href="A--X.html">`},
+			nil,
+		},
+	}
+	for _, test := range tests {
+		x := NewReportIndex(test.formats)
+		x.Add("A", "X", "BUY", 0.125)
+		x.Add("A", "Y", "HOLD", math.NaN())
+		x.Add("B", "Y", "SELL", -0.05)
+		x.Add("C", "X", "SELL", 0)
+		x.Retain([]string{"A", "B"})
+
+		path := filepath.Join(t.TempDir(), "index.html")
+		if err := x.WriteToFile(path); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(string(data), expected) {
+				t.Fatalf("%v: actual index\n%s\nexpected %s in it", test.formats, data, expected)
+			}
+		}
+		for _, missing := range test.missing {
+			if strings.Contains(string(data), missing) {
+				t.Fatalf("%v: actual index\n%s\nexpected no %s in it", test.formats, data, missing)
+			}
+		}
+	}
+}
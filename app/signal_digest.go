@@ -0,0 +1,226 @@
+package app
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/vextasy/strategise/domain"
+	"github.com/vextasy/strategise/internal"
+)
+
+// SignalDigest summarises a run of the signal ledger: the actions that changed
+// to BUY or SELL since the previous run and the assets on which the strategies
+// disagree.
+type SignalDigest struct {
+	RunAt         time.Time
+	Buys          []DigestEntry
+	Sells         []DigestEntry
+	Disagreements []Disagreement
+}
+
+// DigestEntry is a changed signal and the report it came from.
+type DigestEntry struct {
+	domain.SignalChange
+
+	// Report is the file name of the strategy's report for the asset.
+	Report string
+}
+
+// PreviousAction is the action before the change, or NEW if there was none.
+func (e DigestEntry) PreviousAction() string {
+	if e.Previous == nil {
+		return "NEW"
+	}
+	return e.Previous.Action
+}
+
+// Disagreement lists the strategies recommending opposite actions for an asset.
+type Disagreement struct {
+	Asset string
+	Buys  []DigestEntry
+	Sells []DigestEntry
+}
+
+// NewSignalDigest returns the digest of the most recent run in the ledger.
+func NewSignalDigest(ledger *SignalLedger) (*SignalDigest, error) {
+	runs, err := ledger.Runs()
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return &SignalDigest{}, nil
+	}
+	return NewSignalDigestFor(ledger, runs[len(runs)-1])
+}
+
+// NewSignalDigestFor returns the digest of the given run in the ledger.
+func NewSignalDigestFor(ledger *SignalLedger, runAt time.Time) (*SignalDigest, error) {
+	d := &SignalDigest{RunAt: runAt}
+
+	changes, err := ledger.ChangedIn(runAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		entry := newDigestEntry(change)
+		switch change.Current.Action {
+		case "BUY":
+			d.Buys = append(d.Buys, entry)
+		case "SELL":
+			d.Sells = append(d.Sells, entry)
+		}
+	}
+
+	signals, err := ledger.Signals()
+	if err != nil {
+		return nil, err
+	}
+	byAsset := make(map[string]*Disagreement)
+	for _, signal := range signals {
+		if !signal.RunAt.Equal(runAt) {
+			continue
+		}
+		disagreement, ok := byAsset[signal.Asset]
+		if !ok {
+			disagreement = &Disagreement{Asset: signal.Asset}
+			byAsset[signal.Asset] = disagreement
+		}
+		entry := newDigestEntry(domain.SignalChange{Current: signal})
+		switch signal.Action {
+		case "BUY":
+			disagreement.Buys = append(disagreement.Buys, entry)
+		case "SELL":
+			disagreement.Sells = append(disagreement.Sells, entry)
+		}
+	}
+	for _, disagreement := range byAsset {
+		if len(disagreement.Buys) > 0 && len(disagreement.Sells) > 0 {
+			d.Disagreements = append(d.Disagreements, *disagreement)
+		}
+	}
+	sort.Slice(d.Disagreements, func(i, j int) bool {
+		return d.Disagreements[i].Asset < d.Disagreements[j].Asset
+	})
+
+	return d, nil
+}
+
+func newDigestEntry(change domain.SignalChange) DigestEntry {
+	return DigestEntry{
+		SignalChange: change,
+		Report:       internal.ReportFilename(change.Current.Asset, change.Current.Strategy),
+	}
+}
+
+// WriteToFiles writes the digest as digest.html and digest.md in the directory,
+// alongside the reports that it links to.
+func (d *SignalDigest) WriteToFiles(dir string) error {
+	for name, write := range map[string]func(io.Writer) error{
+		"digest.html": d.WriteHtml,
+		"digest.md":   d.WriteMarkdown,
+	} {
+		fd, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		err = write(fd)
+		fd.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteHtml writes the digest as an HTML page.
+func (d *SignalDigest) WriteHtml(w io.Writer) error {
+	return digestHtmlTemplate.Execute(w, d)
+}
+
+// WriteMarkdown writes the digest as Markdown.
+func (d *SignalDigest) WriteMarkdown(w io.Writer) error {
+	return digestMarkdownTemplate.Execute(w, d)
+}
+
+// digestHtmlTemplate lays out a signal digest as an HTML page.
+var digestHtmlTemplate = htmltemplate.Must(htmltemplate.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Signal Digest {{ .RunAt.Format "2006-01-02 15:04" }}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+</style>
+</head>
+<body>
+<h1>Signal Digest {{ .RunAt.Format "2006-01-02 15:04" }}</h1>
+{{- define "changes" }}
+{{- if . }}
+<table>
+<tr><th>Asset</th><th>Strategy</th><th>Previous</th><th>Date</th><th>Price</th></tr>
+{{- range . }}
+<tr><td>{{ .Current.Asset }}</td><td><a href="{{ .Report }}">{{ .Current.Strategy }}</a></td><td>{{ .PreviousAction }}</td><td>{{ .Current.Date.Format "2006-01-02" }}</td><td>{{ printf "%.2f" .Current.Price }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<p>None.</p>
+{{- end }}
+{{- end }}
+<h2>New BUYs</h2>
+{{- template "changes" .Buys }}
+<h2>New SELLs</h2>
+{{- template "changes" .Sells }}
+<h2>Disagreements</h2>
+{{- if .Disagreements }}
+<table>
+<tr><th>Asset</th><th>BUY</th><th>SELL</th></tr>
+{{- range .Disagreements }}
+<tr><td>{{ .Asset }}</td><td>{{ range .Buys }}<a href="{{ .Report }}">{{ .Current.Strategy }}</a><br>{{ end }}</td><td>{{ range .Sells }}<a href="{{ .Report }}">{{ .Current.Strategy }}</a><br>{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<p>None.</p>
+{{- end }}
+</body>
+</html>
+`))
+
+// digestMarkdownTemplate lays out a signal digest as Markdown. Text in the
+// table cells is escaped so that a | in a name does not end the cell.
+var digestMarkdownTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"cell": func(s string) string { return strings.ReplaceAll(s, "|", `\|`) },
+}).Parse(`# Signal Digest {{ .RunAt.Format "2006-01-02 15:04" }}
+{{ define "changes" }}
+{{- if . }}
+| Asset | Strategy | Previous | Date | Price |
+|---|---|---|---|---|
+{{- range . }}
+| {{ cell .Current.Asset }} | [{{ cell .Current.Strategy }}]({{ cell .Report }}) | {{ .PreviousAction }} | {{ .Current.Date.Format "2006-01-02" }} | {{ printf "%.2f" .Current.Price }} |
+{{- end }}
+{{- else }}
+None.
+{{- end }}
+{{ end }}
+## New BUYs
+{{ template "changes" .Buys }}
+## New SELLs
+{{ template "changes" .Sells }}
+## Disagreements
+{{ if .Disagreements }}
+| Asset | BUY | SELL |
+|---|---|---|
+{{- range .Disagreements }}
+| {{ cell .Asset }} | {{ range $i, $e := .Buys }}{{ if $i }}, {{ end }}[{{ cell $e.Current.Strategy }}]({{ cell $e.Report }}){{ end }} | {{ range $i, $e := .Sells }}{{ if $i }}, {{ end }}[{{ cell $e.Current.Strategy }}]({{ cell $e.Report }}){{ end }} |
+{{- end }}
+{{- else }}
+None.
+{{- end }}
+`))
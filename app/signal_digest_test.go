@@ -0,0 +1,91 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vextasy/strategise/domain"
+)
+
+func TestSignalDigest(t *testing.T) {
+	dir := t.TempDir()
+	l := NewSignalLedger(filepath.Join(dir, "signals.jsonl"))
+	run1 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	run2 := run1.AddDate(0, 0, 1)
+	signal := func(runAt time.Time, asset, strategy, action string) domain.Signal {
+		return domain.Signal{RunAt: runAt, Asset: asset, Strategy: strategy, Action: action, Date: runAt, Price: 12.345}
+	}
+	err := l.Record(
+		signal(run1, "A", "X", "HOLD"),
+		signal(run1, "B|C", "X", "SELL"),
+		signal(run2, "A", "X", "BUY"),
+		signal(run2, "A", "Y", "SELL"),
+		signal(run2, "B|C", "X", "SELL"),
+		signal(run2, "B|C", "Y|Z", "BUY"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewSignalDigest(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.RunAt.Equal(run2) || len(d.Buys) != 2 || len(d.Sells) != 1 {
+		t.Fatalf("actual digest of %v with %d buys and %d sells", d.RunAt, len(d.Buys), len(d.Sells))
+	}
+	if b := d.Buys[0]; b.Current.Asset != "A" || b.PreviousAction() != "HOLD" || b.Report != "A--X.html" {
+		t.Fatalf("actual first buy %+v", b)
+	}
+	if b := d.Buys[1]; b.Current.Asset != "B|C" || b.PreviousAction() != "NEW" {
+		t.Fatalf("actual second buy %+v", b)
+	}
+	if len(d.Disagreements) != 2 || d.Disagreements[0].Asset != "A" || d.Disagreements[1].Asset != "B|C" {
+		t.Fatalf("actual disagreements %+v", d.Disagreements)
+	}
+
+	var markdown bytes.Buffer
+	if err := d.WriteMarkdown(&markdown); err != nil {
+		t.Fatal(err)
+	}
+	// A | in a name is escaped so that it does not end the table cell.
+	for _, expected := range []string{
+		"# Signal Digest 2024-05-02 12:00",
+		"| A | [X](A--X.html) | HOLD | 2024-05-02 | 12.35 |",
+		`| B\|C | [Y\|Z](B\|C--Y\|Z.html) | NEW | 2024-05-02 | 12.35 |`,
+		`| B\|C | [Y\|Z](B\|C--Y\|Z.html) | [X](B\|C--X.html) |`,
+	} {
+		if !strings.Contains(markdown.String(), expected) {
+			t.Fatalf("actual Markdown\n%s\nexpected %s in it", &markdown, expected)
+		}
+	}
+
+	if err := d.WriteToFiles(dir); err != nil {
+		t.Fatal(err)
+	}
+	html, err := os.ReadFile(filepath.Join(dir, "digest.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<td><a href="A--X.html">X</a></td><td>HOLD</td>`; !strings.Contains(string(html), expected) {
+		t.Fatalf("actual HTML\n%s\nexpected %s in it", html, expected)
+	}
+}
+
+func TestSignalDigestEmpty(t *testing.T) {
+	d, err := NewSignalDigest(NewSignalLedger(filepath.Join(t.TempDir(), "signals.jsonl")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var markdown bytes.Buffer
+	if err := d.WriteMarkdown(&markdown); err != nil {
+		t.Fatal(err)
+	}
+	if count := strings.Count(markdown.String(), "None."); count != 3 {
+		t.Fatalf("actual Markdown\n%s\nexpected None. for each section", &markdown)
+	}
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vextasy/strategise/domain"
+)
+
+// SignalLedger is a persistent history of signals, stored as JSON Lines
+// with one signal per line. Signals are only ever appended.
+type SignalLedger struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewSignalLedger returns a ledger stored in the file at path.
+// The file is created when the first signal is recorded.
+func NewSignalLedger(path string) *SignalLedger {
+	return &SignalLedger{path: path}
+}
+
+// Record appends the signals to the ledger. It is safe for concurrent use.
+func (l *SignalLedger) Record(signals ...domain.Signal) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	for _, signal := range signals {
+		if err := encoder.Encode(signal); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// Signals returns every signal in the ledger in the order it was recorded.
+// An empty slice is returned if nothing has been recorded yet.
+func (l *SignalLedger) Signals() ([]domain.Signal, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var signals []domain.Signal
+	decoder := json.NewDecoder(f)
+	for {
+		var signal domain.Signal
+		err := decoder.Decode(&signal)
+		if err == io.EOF {
+			return signals, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		signals = append(signals, signal)
+	}
+}
+
+// Runs returns the distinct run times in the ledger, oldest first.
+func (l *SignalLedger) Runs() ([]time.Time, error) {
+	signals, err := l.Signals()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]bool)
+	var runs []time.Time
+	for _, signal := range signals {
+		if !seen[signal.RunAt.UnixNano()] {
+			seen[signal.RunAt.UnixNano()] = true
+			runs = append(runs, signal.RunAt)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Before(runs[j]) })
+	return runs, nil
+}
+
+// Latest returns the most recent signal for each asset and strategy,
+// ordered by asset and then strategy.
+func (l *SignalLedger) Latest() ([]domain.Signal, error) {
+	signals, err := l.Signals()
+	if err != nil {
+		return nil, err
+	}
+	var latest []domain.Signal
+	for _, history := range histories(signals) {
+		latest = append(latest, history[len(history)-1])
+	}
+	return latest, nil
+}
+
+// ChangedSinceLastRun returns the signals of the most recent run whose action
+// differs from the previous signal for the same asset and strategy, including
+// those with no previous signal. They are ordered by asset and then strategy.
+func (l *SignalLedger) ChangedSinceLastRun() ([]domain.SignalChange, error) {
+	runs, err := l.Runs()
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return l.ChangedIn(runs[len(runs)-1])
+}
+
+// ChangedIn returns the signals of the given run whose action differs from
+// the previous signal for the same asset and strategy.
+func (l *SignalLedger) ChangedIn(runAt time.Time) ([]domain.SignalChange, error) {
+	signals, err := l.Signals()
+	if err != nil {
+		return nil, err
+	}
+	var changes []domain.SignalChange
+	for _, history := range histories(signals) {
+		for i, signal := range history {
+			if !signal.RunAt.Equal(runAt) {
+				continue
+			}
+			if i == 0 {
+				changes = append(changes, domain.SignalChange{Current: signal})
+				continue
+			}
+			if previous := history[i-1]; previous.Action != signal.Action {
+				changes = append(changes, domain.SignalChange{Previous: &previous, Current: signal})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// histories groups the signals by asset and strategy, ordered by asset and
+// then strategy. Each history is ordered by run time.
+func histories(signals []domain.Signal) [][]domain.Signal {
+	type key struct{ asset, strategy string }
+	groups := make(map[key][]domain.Signal)
+	var keys []key
+	for _, signal := range signals {
+		k := key{signal.Asset, signal.Strategy}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], signal)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].asset != keys[j].asset {
+			return keys[i].asset < keys[j].asset
+		}
+		return keys[i].strategy < keys[j].strategy
+	})
+
+	result := make([][]domain.Signal, len(keys))
+	for i, k := range keys {
+		history := groups[k]
+		sort.SliceStable(history, func(i, j int) bool { return history[i].RunAt.Before(history[j].RunAt) })
+		result[i] = history
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vextasy/strategise/domain"
+)
+
+func TestSignalLedger(t *testing.T) {
+	l := NewSignalLedger(filepath.Join(t.TempDir(), "signals.jsonl"))
+
+	// An empty ledger has nothing to report.
+	if signals, err := l.Signals(); err != nil || len(signals) != 0 {
+		t.Fatalf("actual %v, %v expected no signals", signals, err)
+	}
+	if changes, err := l.ChangedSinceLastRun(); err != nil || len(changes) != 0 {
+		t.Fatalf("actual %v, %v expected no changes", changes, err)
+	}
+
+	run1 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	run2 := run1.AddDate(0, 0, 1)
+	run3 := run1.AddDate(0, 0, 2)
+	signal := func(runAt time.Time, asset, strategy, action string) domain.Signal {
+		return domain.Signal{RunAt: runAt, Asset: asset, Strategy: strategy, Action: action, Indicators: map[string]float64{"RSI": 50}}
+	}
+	// The runs are recorded out of order.
+	for _, signals := range [][]domain.Signal{
+		{signal(run1, "A", "X", "BUY"), signal(run1, "A", "Y", "HOLD")},
+		{signal(run3, "A", "X", "SELL")},
+		{signal(run2, "B", "X", "BUY"), signal(run2, "A", "X", "BUY"), signal(run2, "A", "Y", "SELL")},
+	} {
+		if err := l.Record(signals...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := l.Runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 3 || !runs[0].Equal(run1) || !runs[1].Equal(run2) || !runs[2].Equal(run3) {
+		t.Fatalf("actual runs %v", runs)
+	}
+
+	latest, err := l.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []domain.Signal{signal(run3, "A", "X", "SELL"), signal(run2, "A", "Y", "SELL"), signal(run2, "B", "X", "BUY")}
+	if len(latest) != len(expected) {
+		t.Fatalf("actual latest %+v expected %+v", latest, expected)
+	}
+	for i := range latest {
+		if !sameSignal(latest[i], expected[i]) || latest[i].Indicators["RSI"] != 50 {
+			t.Fatalf("actual latest %+v expected %+v", latest[i], expected[i])
+		}
+	}
+
+	tests := []struct {
+		runAt    time.Time
+		expected []domain.SignalChange // Only the asset, strategy and actions are compared
+	}{
+		{run1, []domain.SignalChange{
+			{Current: signal(run1, "A", "X", "BUY")},
+			{Current: signal(run1, "A", "Y", "HOLD")},
+		}},
+		{run2, []domain.SignalChange{
+			{Previous: ptr(signal(run1, "A", "Y", "HOLD")), Current: signal(run2, "A", "Y", "SELL")},
+			{Current: signal(run2, "B", "X", "BUY")},
+		}},
+		{run3, []domain.SignalChange{
+			{Previous: ptr(signal(run2, "A", "X", "BUY")), Current: signal(run3, "A", "X", "SELL")},
+		}},
+		{run3.AddDate(0, 0, 1), nil},
+	}
+	for _, test := range tests {
+		changes, err := l.ChangedIn(test.runAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changes) != len(test.expected) {
+			t.Fatalf("%v: actual changes %+v expected %+v", test.runAt, changes, test.expected)
+		}
+		for i, change := range changes {
+			e := test.expected[i]
+			if !sameSignal(change.Current, e.Current) || (change.Previous == nil) != (e.Previous == nil) ||
+				(e.Previous != nil && !sameSignal(*change.Previous, *e.Previous)) {
+				t.Fatalf("%v: actual change %+v expected %+v", test.runAt, change, e)
+			}
+		}
+	}
+
+	last, err := l.ChangedSinceLastRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(last) != 1 || last[0].Current.Action != "SELL" {
+		t.Fatalf("actual changes since the last run %+v", last)
+	}
+}
+
+func TestSignalLedgerNaNIndicator(t *testing.T) {
+	l := NewSignalLedger(filepath.Join(t.TempDir(), "signals.jsonl"))
+
+	// JSON cannot hold NaN, so the indicators must be finite to be recorded.
+	s := domain.Signal{Asset: "A", Strategy: "X", Action: "HOLD", Indicators: map[string]float64{"RSI": math.NaN()}}
+	if err := l.Record(s); err == nil {
+		t.Fatal("expected an error recording a NaN indicator")
+	}
+	if signals, err := l.Signals(); err != nil || len(signals) != 0 {
+		t.Fatalf("actual %+v, %v expected nothing recorded", signals, err)
+	}
+}
+
+// sameSignal reports whether the signals are for the same run, asset, strategy and action.
+func sameSignal(a, b domain.Signal) bool {
+	return a.RunAt.Equal(b.RunAt) && a.Asset == b.Asset && a.Strategy == b.Strategy && a.Action == b.Action
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/cinar/indicator/v2/strategy"
 	"github.com/cinar/indicator/v2/strategy/compound"
 	"github.com/cinar/indicator/v2/strategy/momentum"
 	"github.com/cinar/indicator/v2/strategy/trend"
 	"github.com/cinar/indicator/v2/strategy/volatility"
+	"github.com/vextasy/strategise/analysis"
 	"github.com/vextasy/strategise/app"
+	"github.com/vextasy/strategise/internal"
 	"github.com/vextasy/strategise/strategy/combined"
+	"github.com/vextasy/strategise/strategy/decorator"
+	"github.com/vextasy/strategise/strategy/regime"
 	alt_trend "github.com/vextasy/strategise/strategy/trend"
 )
 
@@ -17,11 +26,21 @@ const datadir = "/Users/john/Downloads/PPData"
 const backtestdir = "/Users/john/Downloads/PPBacktest"
 
 func main() {
+	benchmarkName := flag.String("benchmark", "", "name of a portfolio asset to measure the strategies against")
+	benchmarkCsv := flag.String("benchmark-csv", "", "CSV file of date,close prices to measure the strategies against")
+	logFlags := internal.NewLogFlags(flag.CommandLine)
+	flag.Parse()
+
+	if err := logFlags.Check(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logFlags.NewLogger(os.Stderr))
 
 	// Read the Portfolio Performance XML file
 	r, err := app.NewPortfolioPerformanceRepository(datadir + "/portfolio.xml")
 	if err != nil {
-		fmt.Println("Error reading XML file:", err)
+		slog.Error("reading XML file", "err", err)
 		return
 	}
 
@@ -29,13 +48,26 @@ func main() {
 	b.LastDays = 365
 	b.Strategies = []strategy.Strategy{
 		combined.NewWishfulThinkingStrategyWith(30, 70),
+		combined.NewWishfulThinkingStrategyWithUlcerIndex(30, 70, 5, 10),
 		combined.NewAwesomeMbuStrategyWith(40, 60),
+		combined.NewAwesomeMbuStrategyWithCombination(40, 60, combined.OrCombination,
+			combined.MacdComponent,
+			combined.AwesomeOscillatorComponent,
+			combined.RsiComponent),
+		combined.NewAwesomeMbuStrategyWithCombination(40, 60, combined.RsiFilterCombination,
+			combined.MacdComponent,
+			combined.AwesomeOscillatorComponent,
+			combined.RsiComponent),
 		strategy.NewBuyAndHoldStrategy(),
 		volatility.NewBollingerBandsStrategy(),
 		////volatility.NewSuperTrendStrategy(),
 		trend.NewMacdStrategy(),
 		trend.NewMacdStrategyWith(5, 35, 5),
 		alt_trend.NewBoldMacdStrategy(),
+		decorator.NewTrailingStopStrategy(alt_trend.NewBoldMacdStrategy(), 0.1),
+		decorator.NewFilterStrategy(alt_trend.NewBoldMacdStrategy(),
+			decorator.NewPersistenceFilter(2),
+			decorator.NewMinimumHoldFilter(10)),
 		trend.NewApoStrategy(),
 		trend.NewAroonStrategy(),
 		//trend.NewBopStrategy(),
@@ -56,11 +88,40 @@ func main() {
 		////momentum.NewStochasticRsiStrategy(),
 		momentum.NewTripleRsiStrategy(),
 		compound.NewMacdRsiStrategy(),
+		regime.NewSwitchingStrategy(),
+		regime.NewSwitchingStrategyWith(regime.NewSlopeClassifier(),
+			trend.NewAroonStrategy(),
+			volatility.NewBollingerBandsStrategy()),
 	}
 	err = b.Run()
 
 	if err != nil {
-		fmt.Println("Error running backtest:", err)
+		slog.Error("running backtest", "err", err)
+		return
+	}
+
+	// Optionally measure every strategy against a benchmark.
+	var benchmark *analysis.Benchmark
+	switch {
+	case *benchmarkCsv != "":
+		name := strings.TrimSuffix(filepath.Base(*benchmarkCsv), filepath.Ext(*benchmarkCsv))
+		benchmark, err = analysis.NewCsvBenchmark(name, *benchmarkCsv)
+	case *benchmarkName != "":
+		benchmark, err = analysis.NewRepositoryBenchmark(r, *benchmarkName)
+	default:
+		return
+	}
+	if err != nil {
+		slog.Error("reading benchmark", "err", err)
+		return
+	}
+
+	bb := analysis.NewBenchmarkBacktest(r, benchmark, backtestdir)
+	bb.LastDays = b.LastDays
+	bb.Strategies = b.Strategies
+	_, err = bb.Run()
+	if err != nil {
+		slog.Error("running benchmark backtest", "err", err)
 		return
 	}
 }
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/cinar/indicator/v2/strategy/momentum"
+	"github.com/cinar/indicator/v2/strategy/trend"
+	"github.com/vextasy/strategise/analysis"
+	"github.com/vextasy/strategise/app"
+	"github.com/vextasy/strategise/internal"
+	"github.com/vextasy/strategise/strategy/combined"
+	alt_trend "github.com/vextasy/strategise/strategy/trend"
+)
+
+const datadir = "/Users/john/Downloads/PPData"
+const reportdir = "/Users/john/Downloads/PPReport"
+
+func main() {
+	window := flag.Int("window", analysis.DefaultCorrelationWindow, "number of days over which returns are correlated")
+	threshold := flag.Float64("threshold", analysis.DefaultCorrelationThreshold, "correlation at or above which assets move together")
+	lastDays := flag.Int("days", 30, "number of most recent days checked for correlated Buy signals")
+	history := flag.Int("history", 365, "number of most recent days over which the correlations are followed")
+	step := flag.Int("step", 21, "number of days between the correlations followed")
+	logFlags := internal.NewLogFlags(flag.CommandLine)
+	flag.Parse()
+
+	if err := logFlags.Check(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logFlags.NewLogger(os.Stderr))
+
+	// Read the Portfolio Performance XML file
+	r, err := app.NewPortfolioPerformanceRepository(datadir + "/portfolio.xml")
+	if err != nil {
+		slog.Error("reading XML file", "err", err)
+		return
+	}
+
+	assets, _ := r.Assets()
+	calendar, err := analysis.NewCalendar(r, assets)
+	if err != nil {
+		slog.Error("reading asset snapshots", "err", err)
+		return
+	}
+	last := len(calendar.Dates) - 1
+	if last < 0 {
+		slog.Error("no prices to correlate")
+		return
+	}
+
+	strategies := []strategy.Strategy{
+		combined.NewWishfulThinkingStrategyWith(30, 70),
+		combined.NewAwesomeMbuStrategyWith(40, 60),
+		alt_trend.NewBoldMacdStrategy(),
+		trend.NewMacdStrategy(),
+		momentum.NewRsiStrategy(),
+	}
+
+	// Mark the recent calendar days on which any strategy signals Buy for each asset.
+	buys := make(map[string][]bool, len(assets))
+	for _, name := range assets {
+		snapshots, err := r.Get(name)
+		if err != nil {
+			slog.Error("reading asset snapshots", "err", err)
+			return
+		}
+		snapshotSlice := helper.ChanToSlice(snapshots)
+
+		buys[name] = make([]bool, len(calendar.Dates))
+		for _, st := range strategies {
+			markBuys(calendar, buys[name], snapshotSlice, normalizedActions(st, snapshotSlice), last-*lastDays)
+		}
+	}
+
+	matrices := calendar.RollingCorrelationMatrix(assets, last-*history, *window, *step)
+	matrix := matrices[len(matrices)-1]
+	clusters := matrix.Cluster(*threshold)
+
+	var order []string
+	for _, cluster := range clusters {
+		order = append(order, cluster...)
+	}
+	rolling := make([]*analysis.CorrelationMatrix, len(matrices)-1)
+	for i, m := range matrices[:len(matrices)-1] {
+		rolling[i] = m.Reorder(order)
+	}
+
+	report := &analysis.CorrelationReport{
+		Date:      calendar.Dates[last],
+		Window:    *window,
+		Threshold: *threshold,
+		Matrix:    matrix.Reorder(order),
+		Rolling:   rolling,
+		Clusters:  clusters,
+		Buys:      calendar.CorrelatedBuys(buys, *window, *threshold),
+	}
+	err = report.WriteToFile(reportdir + "/correlation.html")
+	if err != nil {
+		slog.Error("writing report", "err", err)
+		return
+	}
+
+	for _, b := range report.Buys {
+		for _, p := range b.Pairs {
+			fmt.Printf("%s BUY on correlated %s and %s (%.2f)\n", b.Date.Format("2006-01-02"), p.A, p.B, p.Correlation)
+		}
+	}
+}
+
+// normalizedActions runs the strategy over the snapshots and returns its
+// normalized actions. Not every strategy normalizes its own, and a repeated
+// Buy is not a new signal.
+func normalizedActions(st strategy.Strategy, snapshots []*asset.Snapshot) []strategy.Action {
+	return helper.ChanToSlice(strategy.NormalizeActions(st.Compute(helper.SliceToChan(snapshots))))
+}
+
+// markBuys marks the calendar days after from on which the normalized actions are Buy.
+func markBuys(calendar *analysis.Calendar, buys []bool, snapshots []*asset.Snapshot, actions []strategy.Action, from int) {
+	for i, s := range snapshots {
+		if i >= len(actions) || actions[i] != strategy.Buy {
+			continue
+		}
+		if day, ok := calendar.Index(s.Date); ok && day > from {
+			buys[day] = true
+		}
+	}
+}
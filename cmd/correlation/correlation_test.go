@@ -0,0 +1,69 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+
+	"github.com/vextasy/strategise/analysis"
+	"github.com/vextasy/strategise/app"
+	"github.com/vextasy/strategise/domain"
+)
+
+// rawStrategy repeats its actions without normalizing them.
+type rawStrategy []strategy.Action
+
+func (s rawStrategy) Name() string { return "Raw" }
+
+func (s rawStrategy) Compute(c <-chan *asset.Snapshot) <-chan strategy.Action {
+	i := 0
+	return helper.Map(c, func(*asset.Snapshot) strategy.Action {
+		action := s[i%len(s)]
+		i++
+		return action
+	})
+}
+
+func (s rawStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
+	return helper.NewReport(s.Name(), asset.SnapshotsAsDates(c))
+}
+
+func TestMarkBuys(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := make([]*asset.Snapshot, 6)
+	for i := range snapshots {
+		snapshots[i] = &asset.Snapshot{Date: start.AddDate(0, 0, i), Close: 10}
+	}
+	r := app.NewMemoryRepository()
+	r.Add(domain.SecurityInfo{Name: "A"}, snapshots...)
+	calendar, err := analysis.NewCalendar(r, []string{"A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the first of the repeated Buy actions is a signal.
+	st := rawStrategy{strategy.Buy, strategy.Buy, strategy.Sell}
+	if expected := []strategy.Action{strategy.Buy, strategy.Hold, strategy.Sell, strategy.Buy, strategy.Hold, strategy.Sell}; !slices.Equal(normalizedActions(st, snapshots), expected) {
+		t.Fatalf("actual actions %v expected %v", normalizedActions(st, snapshots), expected)
+	}
+
+	tests := []struct {
+		from     int
+		expected []bool
+	}{
+		{-1, []bool{true, false, false, true, false, false}},
+		{0, []bool{false, false, false, true, false, false}},
+		{3, []bool{false, false, false, false, false, false}},
+	}
+	for _, test := range tests {
+		buys := make([]bool, len(calendar.Dates))
+		markBuys(calendar, buys, snapshots, normalizedActions(st, snapshots), test.from)
+		if !slices.Equal(buys, test.expected) {
+			t.Fatalf("after day %d: actual %v expected %v", test.from, buys, test.expected)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/cinar/indicator/v2/strategy/compound"
+	"github.com/cinar/indicator/v2/strategy/momentum"
+	"github.com/cinar/indicator/v2/strategy/trend"
+	"github.com/vextasy/strategise/analysis"
+	"github.com/vextasy/strategise/app"
+	"github.com/vextasy/strategise/internal"
+	"github.com/vextasy/strategise/strategy/combined"
+	alt_trend "github.com/vextasy/strategise/strategy/trend"
+)
+
+const datadir = "/Users/john/Downloads/PPData"
+
+func main() {
+	iterations := flag.Int("iterations", analysis.DefaultRobustnessIterations, "number of resampled paths per method")
+	seed := flag.Int64("seed", 1, "random number generator seed")
+	blockSize := flag.Int("block", analysis.DefaultRobustnessBlockSize, "bootstrap block size in days")
+	noise := flag.Float64("noise", analysis.DefaultRobustnessNoise, "standard deviation of the relative price noise")
+	confidence := flag.Float64("confidence", analysis.DefaultRobustnessConfidence, "confidence level of the reported intervals, between 0 and 1")
+	logFlags := internal.NewLogFlags(flag.CommandLine)
+	flag.Parse()
+
+	if err := logFlags.Check(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logFlags.NewLogger(os.Stderr))
+
+	if err := (&analysis.Robustness{Iterations: *iterations, BlockSize: *blockSize, Noise: *noise, Confidence: *confidence}).Validate(); err != nil {
+		slog.Error("checking parameters", "err", err)
+		os.Exit(2)
+	}
+
+	// Read the Portfolio Performance XML file
+	r, err := app.NewPortfolioPerformanceRepository(datadir + "/portfolio.xml")
+	if err != nil {
+		slog.Error("reading XML file", "err", err)
+		return
+	}
+
+	strategies := []strategy.Strategy{
+		combined.NewWishfulThinkingStrategyWith(30, 70),
+		combined.NewAwesomeMbuStrategyWith(40, 60),
+		alt_trend.NewBoldMacdStrategy(),
+		trend.NewMacdStrategy(),
+		momentum.NewRsiStrategy(),
+		compound.NewMacdRsiStrategy(),
+	}
+
+	assets, _ := r.Assets()
+	for _, name := range assets {
+		snapshots, err := r.Get(name)
+		if err != nil {
+			slog.Error("reading asset snapshots", "err", err)
+			return
+		}
+		snapshotSlice := helper.ChanToSlice(snapshots)
+
+		for _, st := range strategies {
+			rt := analysis.NewRobustnessWith(st, *iterations, *seed)
+			rt.BlockSize = *blockSize
+			rt.Noise = *noise
+			rt.Confidence = *confidence
+			results, err := rt.Run(snapshotSlice)
+			if err != nil {
+				slog.Error("testing robustness", "asset", name, "strategy", st.Name(), "err", err)
+				return
+			}
+			for _, res := range results {
+				fmt.Printf("%s\t%s\t%s\treturn %.2f%% [%.2f%%, %.2f%%]\tdrawdown %.2f%% [%.2f%%, %.2f%%]\n",
+					name, st.Name(), res.Method,
+					res.Return.Median*100, res.Return.Low*100, res.Return.High*100,
+					res.Drawdown.Median*100, res.Drawdown.Low*100, res.Drawdown.High*100,
+				)
+			}
+		}
+	}
+}
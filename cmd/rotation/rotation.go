@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/vextasy/strategise/app"
+	"github.com/vextasy/strategise/internal"
+	"github.com/vextasy/strategise/strategy/rotation"
+)
+
+const datadir = "/Users/john/Downloads/PPData"
+const backtestdir = "/Users/john/Downloads/PPBacktest"
+
+func main() {
+	lookback := flag.Int("lookback", rotation.DefaultRotationLookback, "number of days over which momentum is measured")
+	top := flag.Int("top", rotation.DefaultRotationTop, "number of assets held")
+	rebalance := flag.Int("rebalance", rotation.DefaultRotationRebalance, "number of days between rebalances")
+	lastDays := flag.Int("days", 365, "number of most recent days to trade")
+	logFlags := internal.NewLogFlags(flag.CommandLine)
+	flag.Parse()
+
+	if err := logFlags.Check(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logFlags.NewLogger(os.Stderr))
+
+	// Read the Portfolio Performance XML file
+	r, err := app.NewPortfolioPerformanceRepository(datadir + "/portfolio.xml")
+	if err != nil {
+		slog.Error("reading XML file", "err", err)
+		return
+	}
+
+	b := rotation.NewBacktest(r, backtestdir)
+	b.Strategy, err = rotation.NewRotationStrategyWith(*lookback, *top, *rebalance)
+	if err != nil {
+		slog.Error("checking parameters", "err", err)
+		return
+	}
+	b.LastDays = *lastDays
+
+	result, err := b.Run()
+	if err != nil {
+		slog.Error("running rotation backtest", "err", err)
+		return
+	}
+
+	fmt.Println(b.Strategy.Name(), "rebalanced", len(result.Rebalancing), "times over", len(result.Dates), "days")
+}
@@ -0,0 +1,659 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+
+	"github.com/vextasy/strategise/app"
+	"github.com/vextasy/strategise/domain"
+	"github.com/vextasy/strategise/internal"
+	"github.com/vextasy/strategise/strategy/rotation"
+	"github.com/vextasy/strategise/strategy/warmup"
+)
+
+// reportCommand writes an HTML report for each selected asset and strategy.
+func reportCommand(ctx context.Context, args []string) int {
+	fs, o := newFlagSet("report")
+	formatList := fs.String("formats", "html", "comma separated report formats: html, csv, json")
+	if ok, code := parse(fs, o, args); !ok {
+		return code
+	}
+	formats, err := parseFormats(*formatList)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	r, assets, strategies, code := loadSelection(o)
+	if code != exitOK {
+		return code
+	}
+
+	if o.dryRun {
+		for _, assetName := range assets {
+			for _, st := range strategies {
+				for _, format := range formats {
+					fmt.Println("Would write", filepath.Join(o.outdir, internal.ReportFilenameFor(assetName, st.Name(), format)))
+				}
+			}
+		}
+		return exitOK
+	}
+
+	if err := os.MkdirAll(o.outdir, 0755); err != nil {
+		slog.Error("creating output directory", "err", err)
+		return exitFailure
+	}
+	index := app.NewReportIndex(formats)
+	code = runTasks(ctx, o, r, assets, strategies, runReport(r, o.outdir, index, formats))
+
+	err = index.WriteToFile(filepath.Join(o.outdir, "index.html"))
+	if err != nil {
+		slog.Error("writing index", "err", err)
+		return exitFailure
+	}
+	return code
+}
+
+// signalCommand records the latest action of each selected asset and strategy
+// in the signal ledger, then reports the changes and writes a digest.
+func signalCommand(ctx context.Context, args []string) int {
+	fs, o := newFlagSet("signal")
+	ledgerPath := fs.String("ledger", "", "signal ledger file (default <out>/signals.jsonl)")
+	var n notifyOptions
+	n.register(fs)
+	if ok, code := parse(fs, o, args); !ok {
+		return code
+	}
+	if *ledgerPath == "" {
+		*ledgerPath = filepath.Join(o.outdir, "signals.jsonl")
+	}
+	notifiers, err := n.notifiers(o.outdir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	r, assets, strategies, code := loadSelection(o)
+	if code != exitOK {
+		return code
+	}
+
+	if o.dryRun {
+		for _, assetName := range assets {
+			for _, st := range strategies {
+				fmt.Println("Would record", assetName, "strategy:", st.Name(), "in", *ledgerPath)
+			}
+		}
+		return exitOK
+	}
+
+	if err := os.MkdirAll(o.outdir, 0755); err != nil {
+		slog.Error("creating output directory", "err", err)
+		return exitFailure
+	}
+
+	ledger := app.NewSignalLedger(*ledgerPath)
+	runAt := time.Now()
+	code = runTasks(ctx, o, r, assets, strategies, runAction(ledger, runAt))
+	if code == exitInterrupted {
+		return code
+	}
+
+	if printChanges(ledger, runAt) != nil || writeDigest(ledger, runAt, o.outdir) != nil {
+		return exitFailure
+	}
+	if notify(ctx, notifiers, ledger, runAt) != nil {
+		return exitFailure
+	}
+	return code
+}
+
+// backtestCommand backtests the selected strategies on the selected assets.
+func backtestCommand(ctx context.Context, args []string) int {
+	fs, o := newFlagSet("backtest")
+	lastDays := fs.Int("last-days", 365, "number of most recent days to backtest")
+	if ok, code := parse(fs, o, args); !ok {
+		return code
+	}
+
+	r, assets, strategies, code := loadSelection(o)
+	if code != exitOK {
+		return code
+	}
+
+	if o.dryRun {
+		fmt.Printf("Would backtest %d strategies on %d assets over the last %d days into %s\n",
+			len(strategies), len(assets), *lastDays, o.outdir)
+		return exitOK
+	}
+
+	if err := os.MkdirAll(o.outdir, 0755); err != nil {
+		slog.Error("creating output directory", "err", err)
+		return exitFailure
+	}
+
+	b := strategy.NewBacktest(r, o.outdir)
+	b.Names = assets
+	b.Strategies = strategies
+	b.Workers = o.workers
+	b.LastDays = *lastDays
+
+	// The backtest cannot be cancelled once started.
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Run()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			slog.Error("running backtest", "err", err)
+			return exitFailure
+		}
+		return exitOK
+	case <-ctx.Done():
+		slog.Warn("stopped", "err", ctx.Err())
+		return exitInterrupted
+	}
+}
+
+// rotationCommand backtests a relative strength rotation across the selected assets.
+// The -strategy option does not apply.
+func rotationCommand(ctx context.Context, args []string) int {
+	fs, o := newFlagSet("rotation")
+	lookback := fs.Int("lookback", rotation.DefaultRotationLookback, "number of days over which momentum is measured")
+	top := fs.Int("top", rotation.DefaultRotationTop, "number of assets held")
+	rebalance := fs.Int("rebalance", rotation.DefaultRotationRebalance, "number of days between rebalances")
+	lastDays := fs.Int("last-days", 365, "number of most recent days to trade")
+	if ok, code := parse(fs, o, args); !ok {
+		return code
+	}
+	st, err := rotation.NewRotationStrategyWith(*lookback, *top, *rebalance)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	r, err := o.repository()
+	if err != nil {
+		slog.Error("reading portfolio", "err", err)
+		return exitFailure
+	}
+	assets, err := o.assets(r)
+	if err != nil {
+		slog.Error("reading assets", "err", err)
+		return exitFailure
+	}
+	if len(assets) == 0 {
+		slog.Error("no assets match", "pattern", o.asset)
+		return exitFailure
+	}
+
+	if o.dryRun {
+		fmt.Printf("Would backtest %s on %d assets over the last %d days into %s\n",
+			st.Name(), len(assets), *lastDays, o.outdir)
+		return exitOK
+	}
+
+	if err := os.MkdirAll(o.outdir, 0755); err != nil {
+		slog.Error("creating output directory", "err", err)
+		return exitFailure
+	}
+
+	b := rotation.NewBacktest(r, o.outdir)
+	b.Strategy = st
+	b.Names = assets
+	b.LastDays = *lastDays
+
+	// The backtest cannot be cancelled once started.
+	type outcome struct {
+		result *rotation.Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := b.Run()
+		done <- outcome{result, err}
+	}()
+	select {
+	case out := <-done:
+		if out.err != nil {
+			slog.Error("running rotation backtest", "err", out.err)
+			return exitFailure
+		}
+		fmt.Println(st.Name(), "rebalanced", len(out.result.Rebalancing), "times over", len(out.result.Dates), "days")
+		return exitOK
+	case <-ctx.Done():
+		slog.Warn("stopped", "err", ctx.Err())
+		return exitInterrupted
+	}
+}
+
+// listAssetsCommand lists the selected assets, with their security
+// descriptions and positions when asked.
+func listAssetsCommand(ctx context.Context, args []string) int {
+	fs, o := newFlagSet("list-assets")
+	details := fs.Bool("details", false, "show the ISIN, ticker, currency, retired state, last update and shares held")
+	if ok, code := parse(fs, o, args); !ok {
+		return code
+	}
+
+	r, err := o.repository()
+	if err != nil {
+		slog.Error("reading portfolio", "err", err)
+		return exitFailure
+	}
+	securities, err := o.securities(r)
+	if err != nil {
+		slog.Error("reading assets", "err", err)
+		return exitFailure
+	}
+	if !*details {
+		for _, security := range securities {
+			fmt.Println(security.Name)
+		}
+		return exitOK
+	}
+
+	positions, err := r.Positions()
+	if err != nil {
+		slog.Error("reading positions", "err", err)
+		return exitFailure
+	}
+	shares := make(map[string]float64, len(positions))
+	for _, position := range positions {
+		shares[position.Security] = position.Shares
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ASSET\tISIN\tTICKER\tCURRENCY\tRETIRED\tUPDATED\tSHARES")
+	for _, security := range securities {
+		updated := "never"
+		if !security.UpdatedAt.IsZero() {
+			updated = security.UpdatedAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\t%s\t%g\n", security.Name, security.ISIN, security.Ticker,
+			security.Currency, security.Retired, updated, shares[security.Name])
+	}
+	if err := tw.Flush(); err != nil {
+		slog.Error("writing assets", "err", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// listStrategiesCommand lists the selected strategies with their warm-up periods.
+func listStrategiesCommand(ctx context.Context, args []string) int {
+	fs, o := newFlagSet("list-strategies")
+	if ok, code := parse(fs, o, args); !ok {
+		return code
+	}
+
+	for _, st := range o.strategies() {
+		fmt.Printf("%s (warm-up %d)\n", st.Name(), warmup.IdlePeriod(st))
+	}
+	return exitOK
+}
+
+// validateDataCommand checks the price data of the selected assets. Assets
+// without enough data for some of the selected strategies are reported but
+// are not counted as problems.
+func validateDataCommand(ctx context.Context, args []string) int {
+	fs, o := newFlagSet("validate-data")
+	maxGap := fs.Int("max-gap", 10, "largest number of days allowed between consecutive prices")
+	if ok, code := parse(fs, o, args); !ok {
+		return code
+	}
+
+	r, assets, strategies, code := loadSelection(o)
+	if code != exitOK {
+		return code
+	}
+
+	problems := 0
+	for _, assetName := range assets {
+		if ctx.Err() != nil {
+			slog.Warn("stopped", "err", ctx.Err())
+			return exitInterrupted
+		}
+
+		snapshots, err := r.Get(assetName)
+		if err != nil {
+			slog.Error("reading asset snapshots", "asset", assetName, "err", err)
+			problems++
+			continue
+		}
+		snapshotSlice := helper.ChanToSlice(snapshots)
+		found := validateSnapshots(snapshotSlice, *maxGap)
+		for _, problem := range found {
+			fmt.Println(assetName+":", problem)
+		}
+		problems += len(found)
+
+		for _, st := range strategies {
+			if !warmup.HasEnoughData(st, len(snapshotSlice)) {
+				fmt.Printf("%s: note: %d prices, %s needs %d\n", assetName, len(snapshotSlice), st.Name(), warmup.IdlePeriod(st)+1)
+			}
+		}
+	}
+
+	if problems > 0 {
+		fmt.Printf("%d problems found\n", problems)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// watchCommand writes the reports and signals of the selected assets, then
+// waits for the portfolio file to change and writes them again for the
+// assets whose prices changed, until interrupted.
+func watchCommand(ctx context.Context, args []string) int {
+	fs, o := newFlagSet("watch")
+	ledgerPath := fs.String("ledger", "", "signal ledger file (default <out>/signals.jsonl)")
+	poll := fs.Duration("poll", 2*time.Second, "how often to check the portfolio file for changes")
+	settle := fs.Duration("settle", 5*time.Second, "how long the portfolio file must stay unchanged before it is read")
+	formatList := fs.String("formats", "html", "comma separated report formats: html, csv, json")
+	var n notifyOptions
+	n.register(fs)
+	if ok, code := parse(fs, o, args); !ok {
+		return code
+	}
+	formats, err := parseFormats(*formatList)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if *ledgerPath == "" {
+		*ledgerPath = filepath.Join(o.outdir, "signals.jsonl")
+	}
+	notifiers, err := n.notifiers(o.outdir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	strategies := o.strategies()
+	if len(strategies) == 0 {
+		slog.Error("no strategies match", "pattern", o.strategy)
+		return exitFailure
+	}
+
+	if o.dryRun {
+		fmt.Println("Would watch", o.data, "and write reports and signals to", o.outdir)
+		return exitOK
+	}
+
+	if err := os.MkdirAll(o.outdir, 0755); err != nil {
+		slog.Error("creating output directory", "err", err)
+		return exitFailure
+	}
+	state, err := statFile(o.data)
+	if err != nil {
+		slog.Error("reading portfolio", "err", err)
+		return exitFailure
+	}
+
+	ledger := app.NewSignalLedger(*ledgerPath)
+	index := app.NewReportIndex(formats)
+	fingerprints := make(map[string]uint64)
+	for {
+		if refresh(ctx, o, formats, strategies, ledger, index, fingerprints, notifiers) == exitInterrupted {
+			return exitInterrupted
+		}
+
+		slog.Info("waiting for the portfolio to change", "path", o.data)
+		state, err = waitForChange(ctx, o.data, state, *poll, *settle)
+		if ctx.Err() != nil {
+			slog.Warn("stopped", "err", ctx.Err())
+			return exitInterrupted
+		}
+		if err != nil {
+			slog.Error("watching portfolio", "err", err)
+			return exitFailure
+		}
+	}
+}
+
+// refresh reads the portfolio and writes the reports and signals of the
+// assets whose prices changed since the last refresh, followed by the index
+// and the digest, and sends the changed signals to the notifiers. It returns
+// the exit code of the refresh. The fingerprints of the changed assets are
+// only updated when all their reports and signals were written, so that a
+// failed or interrupted refresh is retried at the next change.
+func refresh(ctx context.Context, o *options, formats []string, strategies []strategy.Strategy, ledger *app.SignalLedger, index *app.ReportIndex, fingerprints map[string]uint64, notifiers []app.Notifier) int {
+	r, err := o.repository()
+	if err != nil {
+		slog.Error("reading portfolio", "err", err)
+		return exitFailure
+	}
+	assets, err := o.assets(r)
+	if err != nil {
+		slog.Error("reading assets", "err", err)
+		return exitFailure
+	}
+	changed, updated, err := changedAssets(r, assets, fingerprints)
+	if err != nil {
+		slog.Error("reading asset snapshots", "err", err)
+		return exitFailure
+	}
+	index.Retain(assets)
+	if len(changed) == 0 {
+		slog.Info("no prices changed")
+		return exitOK
+	}
+	slog.Info("prices changed", "assets", len(changed))
+
+	runAt := time.Now()
+	run := combineTasks(runReport(r, o.outdir, index, formats), runAction(ledger, runAt))
+	code := runTasks(ctx, o, r, changed, strategies, run)
+	if code == exitInterrupted {
+		return code
+	}
+	if code == exitOK {
+		for name, f := range updated {
+			fingerprints[name] = f
+		}
+	}
+
+	err = index.WriteToFile(filepath.Join(o.outdir, "index.html"))
+	if err != nil {
+		slog.Error("writing index", "err", err)
+		return exitFailure
+	}
+	if printChanges(ledger, runAt) != nil || writeDigest(ledger, runAt, o.outdir) != nil {
+		return exitFailure
+	}
+	if notify(ctx, notifiers, ledger, runAt) != nil {
+		return exitFailure
+	}
+	return code
+}
+
+// exportPpCommand writes the latest signals in the ledger of the selected
+// assets and strategies into the Portfolio Performance file.
+func exportPpCommand(ctx context.Context, args []string) int {
+	fs, o := newFlagSet("export-pp")
+	ledgerPath := fs.String("ledger", "", "signal ledger file (default <out>/signals.jsonl)")
+	output := fs.String("output", "", "file to write the portfolio to (default the -data file, keeping a copy as .bak)")
+	if ok, code := parse(fs, o, args); !ok {
+		return code
+	}
+	if *ledgerPath == "" {
+		*ledgerPath = filepath.Join(o.outdir, "signals.jsonl")
+	}
+	if *output == "" {
+		*output = o.data
+	}
+
+	latest, err := app.NewSignalLedger(*ledgerPath).Latest()
+	if err != nil {
+		slog.Error("reading signal ledger", "err", err)
+		return exitFailure
+	}
+	assetPattern, _ := newPattern(o.asset)
+	strategyPattern, _ := newPattern(o.strategy)
+	var signals []domain.Signal
+	for _, signal := range latest {
+		if assetPattern.match(signal.Asset) && strategyPattern.match(signal.Strategy) {
+			signals = append(signals, signal)
+		}
+	}
+	if len(signals) == 0 {
+		slog.Error("no signals to export", "ledger", *ledgerPath)
+		return exitFailure
+	}
+
+	if o.dryRun {
+		fmt.Println("Would export", len(signals), "signals into", *output)
+		return exitOK
+	}
+
+	if *output == o.data {
+		doc, err := os.ReadFile(o.data)
+		if err == nil {
+			err = os.WriteFile(o.data+".bak", doc, 0644)
+		}
+		if err != nil {
+			slog.Error("backing up portfolio", "err", err)
+			return exitFailure
+		}
+	}
+	err = app.ExportSignalsToFile(o.data, *output, signals, time.Now())
+	if err != nil {
+		slog.Error("exporting signals", "err", err)
+		return exitFailure
+	}
+	slog.Info("exported signals", "signals", len(signals), "path", *output)
+	return exitOK
+}
+
+// serveCommand serves the dashboard and JSON API until interrupted, reloading
+// the portfolio whenever the file changes.
+func serveCommand(ctx context.Context, args []string) int {
+	fs, o := newFlagSet("serve")
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	poll := fs.Duration("poll", 5*time.Second, "how often to check the portfolio file for changes")
+	if ok, code := parse(fs, o, args); !ok {
+		return code
+	}
+
+	if o.dryRun {
+		fmt.Println("Would serve", o.data, "on", *addr)
+		return exitOK
+	}
+
+	s, err := newServer(o)
+	if err != nil {
+		slog.Error("reading portfolio", "err", err)
+		return exitFailure
+	}
+	go s.watch(ctx, *poll)
+
+	hs := &http.Server{
+		Addr:     *addr,
+		Handler:  s.handler(),
+		ErrorLog: slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hs.Shutdown(shutdown)
+	}()
+
+	slog.Info("serving", "path", o.data, "url", "http://"+*addr+"/")
+	err = hs.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		slog.Error("serving", "err", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// validateSnapshots returns a description of each problem in the snapshots.
+func validateSnapshots(snapshots []*asset.Snapshot, maxGap int) []string {
+	if len(snapshots) == 0 {
+		return []string{"no prices"}
+	}
+
+	var problems []string
+	for i, snapshot := range snapshots {
+		date := snapshot.Date.Format("2006-01-02")
+		if math.IsNaN(snapshot.Close) || math.IsInf(snapshot.Close, 0) || snapshot.Close <= 0 {
+			problems = append(problems, fmt.Sprintf("invalid price %g on %s", snapshot.Close, date))
+		}
+		if i == 0 {
+			continue
+		}
+		previous := snapshots[i-1].Date
+		switch {
+		case !snapshot.Date.After(previous):
+			problems = append(problems, fmt.Sprintf("price on %s is not after the price on %s", date, previous.Format("2006-01-02")))
+		case snapshot.Date.Sub(previous) > time.Duration(maxGap)*24*time.Hour:
+			problems = append(problems, fmt.Sprintf("no prices for %.0f days before %s", snapshot.Date.Sub(previous).Hours()/24, date))
+		}
+	}
+	return problems
+}
+
+// loadSelection loads the portfolio and the selected assets and strategies,
+// returning exitFailure if it cannot be read or nothing is selected.
+func loadSelection(o *options) (domain.Repository, []string, []strategy.Strategy, int) {
+	r, assets, strategies, err := o.load()
+	if err != nil {
+		slog.Error("reading portfolio", "err", err)
+		return nil, nil, nil, exitFailure
+	}
+	if len(assets) == 0 {
+		slog.Error("no assets match", "pattern", o.asset)
+		return nil, nil, nil, exitFailure
+	}
+	if len(strategies) == 0 {
+		slog.Error("no strategies match", "pattern", o.strategy)
+		return nil, nil, nil, exitFailure
+	}
+	return r, assets, strategies, exitOK
+}
+
+// runTasks runs the task for every selected asset and strategy, logs a
+// summary of the failures, and returns the exit code. When ctx is done the
+// failures so far are still summarised before the run is reported stopped.
+func runTasks(ctx context.Context, o *options, r domain.Repository, assets []string, strategies []strategy.Strategy, run task) int {
+	failures, err := runPool(ctx, o, r, assets, strategies, os.Stderr, run)
+	if len(failures) == 0 && err == nil {
+		slog.Info("finished", "assets", len(assets), "strategies", len(strategies))
+		return exitOK
+	}
+
+	for _, f := range failures {
+		if f.strategy == "" {
+			slog.Error("failed", "asset", f.asset, "err", f.err)
+		} else {
+			slog.Error("failed", "asset", f.asset, "strategy", f.strategy, "err", f.err)
+		}
+	}
+	failedAssets := make(map[string]bool)
+	for _, f := range failures {
+		failedAssets[f.asset] = true
+	}
+	if err != nil {
+		if len(failures) > 0 {
+			slog.Error("failures before stopping", "failures", len(failures), "assets", len(failedAssets), "of", len(assets))
+		}
+		slog.Warn("stopped", "err", err)
+		return exitInterrupted
+	}
+	slog.Error("finished with failures", "failures", len(failures), "assets", len(failedAssets), "of", len(assets))
+	return exitFailure
+}
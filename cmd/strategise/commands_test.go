@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/strategy"
+
+	"github.com/vextasy/strategise/app"
+	"github.com/vextasy/strategise/domain"
+	"github.com/vextasy/strategise/internal"
+)
+
+// captureLog sends the default logger's messages to the returned buffer
+// until the test ends.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var logged bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logged, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &logged
+}
+
+func TestRunTasksInterrupted(t *testing.T) {
+	r := newTestRepository(100, "Alpha", "Beta", "Gamma")
+	o := newTestOptions(t, "report", "portfolio.xml", "-workers", "1")
+	logged := captureLog(t)
+
+	// The first task fails and interrupts the run.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	run := func(log *slog.Logger, st strategy.Strategy, assetName string, snapshots *internal.Series[*asset.Snapshot]) error {
+		cancel()
+		return errors.New("broken")
+	}
+
+	code := runTasks(ctx, o, r, []string{"Alpha", "Beta", "Gamma"}, o.strategies(), run)
+	if code != exitInterrupted {
+		t.Fatalf("actual exit code %d expected %d", code, exitInterrupted)
+	}
+	for _, expected := range []string{
+		`msg=failed asset=Alpha strategy="` + testStrategy + `" err=broken`,
+		`msg="failures before stopping"`,
+		`msg=stopped`,
+	} {
+		if !strings.Contains(logged.String(), expected) {
+			t.Fatalf("actual log\n%s\nexpected %s in it", logged, expected)
+		}
+	}
+}
+
+func TestRunReportAndAction(t *testing.T) {
+	r := newTestRepository(100, "Alpha", "Beta")
+	r.Add(domain.SecurityInfo{Name: "Short"}, newTestSnapshots(5)...)
+	outdir := t.TempDir()
+	o := newTestOptions(t, "report", "portfolio.xml", "-out", outdir)
+	captureLog(t)
+
+	index := app.NewReportIndex(internal.ReportFormats)
+	ledger := app.NewSignalLedger(filepath.Join(outdir, "signals.jsonl"))
+	runAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	run := combineTasks(runReport(r, outdir, index, internal.ReportFormats), runAction(ledger, runAt))
+
+	if code := runTasks(context.Background(), o, r, []string{"Alpha", "Beta", "Short"}, o.strategies(), run); code != exitOK {
+		t.Fatalf("actual exit code %d expected %d", code, exitOK)
+	}
+
+	// Every format is written in full from the one computed report.
+	for _, name := range []string{"Alpha", "Beta"} {
+		path := func(format string) string {
+			return filepath.Join(outdir, internal.ReportFilenameFor(name, testStrategy, format))
+		}
+		if _, err := os.Stat(path("html")); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path("csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(string(data), "\n"); lines != 101 {
+			t.Fatalf("%s: actual %d CSV lines expected a header and 100 rows", name, lines)
+		}
+		data, err = os.ReadFile(path("json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var report struct {
+			Title string           `json:"title"`
+			Rows  []map[string]any `json:"rows"`
+		}
+		if err := json.Unmarshal(data, &report); err != nil {
+			t.Fatal(err)
+		}
+		if title := testStrategy + " for " + name + " (GBP, never updated)"; report.Title != title || len(report.Rows) != 100 {
+			t.Fatalf("%s: actual title %q and %d rows expected %q and 100", name, report.Title, len(report.Rows), title)
+		}
+	}
+	// An asset with too few prices for the strategy is skipped.
+	if _, err := os.Stat(filepath.Join(outdir, internal.ReportFilename("Short", testStrategy))); !os.IsNotExist(err) {
+		t.Fatalf("actual %v expected no report for Short", err)
+	}
+
+	signals, err := ledger.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signals) != 2 || signals[0].Asset != "Alpha" || !signals[0].RunAt.Equal(runAt) {
+		t.Fatalf("actual signals %+v", signals)
+	}
+}
@@ -1,140 +1,281 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"math"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/cinar/indicator/v2/asset"
 	"github.com/cinar/indicator/v2/helper"
 	"github.com/cinar/indicator/v2/strategy"
-	"github.com/cinar/indicator/v2/strategy/compound"
-	"github.com/cinar/indicator/v2/strategy/momentum"
-	"github.com/cinar/indicator/v2/strategy/trend"
-	"github.com/cinar/indicator/v2/strategy/volatility"
 
 	"github.com/vextasy/strategise/app"
+	"github.com/vextasy/strategise/domain"
 	"github.com/vextasy/strategise/internal"
+	"github.com/vextasy/strategise/strategy/warmup"
 )
 
 const datadir = "/Users/john/Downloads/PPData"
 const reportdir = "/Users/john/Downloads/PPReport"
 
+// Exit codes returned by the commands.
+const (
+	exitOK          = 0   // Everything succeeded.
+	exitFailure     = 1   // Some of the work failed, or the data has problems.
+	exitUsage       = 2   // The command line is invalid.
+	exitInterrupted = 130 // The run was stopped by Ctrl-C.
+)
+
+// command is a subcommand of the strategise binary. It returns the exit code.
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, args []string) int
+}
+
+var commands = []command{
+	{"report", "write an HTML report for each asset and strategy", reportCommand},
+	{"signal", "record each strategy's latest action in the signal ledger and write a digest", signalCommand},
+	{"backtest", "backtest the strategies on the assets", backtestCommand},
+	{"rotation", "backtest a relative strength rotation across the assets", rotationCommand},
+	{"list-assets", "list the assets in the portfolio", listAssetsCommand},
+	{"list-strategies", "list the available strategies", listStrategiesCommand},
+	{"validate-data", "check the assets' price data for problems", validateDataCommand},
+	{"export-pp", "write the latest signals into the Portfolio Performance file as notes and watchlists", exportPpCommand},
+	{"watch", "write reports and signals again whenever the portfolio's prices change", watchCommand},
+	{"serve", "serve a dashboard and JSON API that compute signals and reports on demand", serveCommand},
+}
+
 func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitUsage)
+	}
 
-	// Read the Portfolio Performance XML file
-	r, err := app.NewPortfolioPerformanceRepository(datadir + "/portfolio.xml")
-	if err != nil {
-		fmt.Println("Error reading XML file:", err)
-		return
-	}
-
-	assets, _ := r.Assets()
-
-	for ai := range assets {
-		strategies := []strategy.Strategy{
-			volatility.NewBollingerBandsStrategy(),
-			////volatility.NewSuperTrendStrategy(),
-			trend.NewMacdStrategy(),
-			trend.NewMacdStrategyWith(5, 35, 5),
-			//trend.NewApoStrategy(),
-			//trend.NewAroonStrategy(),
-			//trend.NewBopStrategy(),
-			////trend.NewCciStrategy(),
-			////trend.NewDemaStrategy(),
-			//trend.NewGoldenCrossStrategy(),
-			////trend.NewKamaStrategy(),
-			//trend.NewKdjStrategy(),
-			trend.NewQstickStrategy(),
-			////trend.NewTrimaStrategy(),
-			////trend.NewTripleMovingAverageCrossoverStrategy(),
-			////trend.NewTrixStrategy(),
-			////trend.NewTsiStrategy(),
-			////trend.NewVwmaStrategy(),
-			momentum.NewRsiStrategy(),
-			momentum.NewRsiStrategyWith(40, 60),
-			//momentum.NewAwesomeOscillatorStrategy(),
-			////momentum.NewStochasticRsiStrategy(),
-			////momentum.NewTripleRsiStrategy(),
-			compound.NewMacdRsiStrategy(),
+	// Stop starting new work on Ctrl-C.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			code := c.run(ctx, os.Args[2:])
+			stop()
+			os.Exit(code)
 		}
-		for si := range strategies {
-			snapshots, err := r.Get(assets[ai])
+	}
+
+	if os.Args[1] != "help" && os.Args[1] != "-h" && os.Args[1] != "--help" {
+		fmt.Fprintln(os.Stderr, "Unknown command:", os.Args[1])
+		usage()
+		os.Exit(exitUsage)
+	}
+	usage()
+}
+
+// usage lists the commands.
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: strategise <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", c.name, c.description)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run strategise <command> -h for the command's flags.")
+}
+
+// recentDays is the number of snapshots over which the index shows a strategy's outcome.
+const recentDays = 63
+
+// runReport returns a task that invokes the strategy's Report and writes it to a file in the outdir
+// in each of the formats, titled with the description of the asset's security in r.
+// The latest action and recent outcome are added to the index.
+func runReport(r domain.Repository, outdir string, index *app.ReportIndex, formats []string) task {
+	return func(log *slog.Logger, st strategy.Strategy, assetName string, data *internal.Series[*asset.Snapshot]) error {
+		log.Debug("writing report")
+		// Detect certain strategies that require a minimum amount of data.
+		if notEnoughData(log, st, data.Len()) {
+			return nil
+		}
+		security, err := r.Security(assetName)
+		if err != nil {
+			log.Error("reading security", "err", err)
+			return fmt.Errorf("reading security: %w", err)
+		}
+		report := st.Report(data.Chan())
+		report.Title = st.Name() + " for " + security.String()
+		reports := func() *helper.Report { return report }
+		if len(formats) > 1 {
+			// Writing a report reads it to the end, so its rows are kept for the other formats.
+			reports = internal.NewReportRows(report).Report
+		}
+		for _, format := range formats {
+			err := writeReportFile(reports(), format, filepath.Join(outdir, internal.ReportFilenameFor(assetName, st.Name(), format)))
 			if err != nil {
-				fmt.Println("Error reading asset snapshots:", err)
-				return
+				log.Error("writing report", "format", format, "err", err)
+				return fmt.Errorf("writing %s report: %w", format, err)
 			}
-			runReport(strategies[si], assets[ai], snapshots)
 		}
+
+		action, outcome := latestActionAndOutcome(st, data, recentDays)
+		index.Add(assetName, st.Name(), action, outcome)
+		return nil
 	}
 }
 
-// duplicateChan creates a new channel containing a copy of the data from 'cin'
-// and returns it and its length.
-func duplicateChan(cin <-chan *asset.Snapshot) (<-chan *asset.Snapshot, <-chan *asset.Snapshot, int) {
-	slice := helper.ChanToSlice(cin)
-	cout0 := helper.SliceToChan(slice)
-	cout1 := helper.SliceToChan(slice)
-	return cout0, cout1, len(slice)
+// writeReportFile writes the report to the file in the format.
+func writeReportFile(report *helper.Report, format, path string) error {
+	fd, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = internal.WriteReport(report, format, fd)
+	if cerr := fd.Close(); err == nil {
+		err = cerr
+	}
+	return err
 }
 
-// runReport invokes the strategy's Report and writes it to a file in the reportdir.
-func runReport(st strategy.Strategy, assetName string, data <-chan *asset.Snapshot) {
-	fmt.Println("R assetName:", assetName, "strategy:", st.Name())
-	// Detect certain strategies that require a minimum amount of data.
-	data, _, datalen := duplicateChan(data)
-	if notEnoughData(st, assetName, datalen) {
-		return
-	}
-	rep := st.Report(data)
-	cfn := internal.CleanFilename
-	filepath := fmt.Sprintf("%s/%s--%s.html", reportdir, cfn(assetName), cfn(st.Name()))
-	err := rep.WriteToFile(filepath)
-	if err != nil {
-		fmt.Println("Error writing report:", err)
-		return
-	}
-}
-
-// runAction computes the strategy's action for each date in the snapshot
-// and writes it to a file in the datadir.
-func runAction(st strategy.Strategy, assetName string, data <-chan *asset.Snapshot) {
-	fmt.Println("A assetName:", assetName, "strategy:", st.Name())
-	data, _, datalen := duplicateChan(data)
-	// Detect certain strategies that require a minimum amount of data.
-	if notEnoughData(st, assetName, datalen) {
-		return
-	}
-	actions := strategy.DenormalizeActions(st.Compute(data))
-	actionSlice := helper.ChanToSlice(actions)
-	action := actionSlice[len(actionSlice)-1]
-	actionString := mkActionString(action)
-	cfn := internal.CleanFilename
-	setActionFile(actionString, cfn(assetName), cfn(st.Name()))
-}
-
-// Some strategies appear to be sensitive to insufficient data.
-// notEnoughData applies some checks and returns true for situations it determines will be problematic.
-func notEnoughData(st strategy.Strategy, assetName string, datalen int) bool {
-	msg := fmt.Sprintf("Ignoring strategy %s for %s due to insufficient data: %s", st.Name(), assetName, st.Name())
-	if st.Name()[0:4] == "MACD" {
-		if datalen < trend.NewMacdStrategy().Macd.Ema2.Period {
-			fmt.Println(msg)
-			return true
+// runAction returns a task that computes the strategy's latest action and
+// records it in the ledger along with the latest closing price and the
+// latest values of the strategy's report columns.
+func runAction(ledger *app.SignalLedger, runAt time.Time) task {
+	return func(log *slog.Logger, st strategy.Strategy, assetName string, data *internal.Series[*asset.Snapshot]) error {
+		log.Debug("computing signal")
+		// Detect certain strategies that require a minimum amount of data.
+		if notEnoughData(log, st, data.Len()) {
+			return nil
+		}
+		err := ledger.Record(computeSignal(st, assetName, data, runAt))
+		if err != nil {
+			log.Error("recording signal", "err", err)
+			return fmt.Errorf("recording signal: %w", err)
 		}
+		return nil
+	}
+}
+
+// computeSignal returns the strategy's signal for the last snapshot of the asset.
+// The data must not be empty.
+func computeSignal(st strategy.Strategy, assetName string, data *internal.Series[*asset.Snapshot], runAt time.Time) domain.Signal {
+	snapshots := data.Values()
+	last := snapshots[len(snapshots)-1]
+
+	return domain.Signal{
+		RunAt:      runAt,
+		Asset:      assetName,
+		Strategy:   st.Name(),
+		Date:       last.Date,
+		Action:     latestAction(st, data),
+		Price:      last.Close,
+		Indicators: indicatorValues(st, data),
+	}
+}
+
+// latestAction returns the strategy's action for the last snapshot as BUY, SELL or HOLD.
+func latestAction(st strategy.Strategy, data *internal.Series[*asset.Snapshot]) string {
+	actions := helper.ChanToSlice(strategy.DenormalizeActions(st.Compute(data.Chan())))
+	return mkActionString(actions[len(actions)-1])
+}
+
+// latestActionAndOutcome returns the strategy's action for the last snapshot
+// as BUY, SELL or HOLD, and the outcome of following the strategy over the
+// last days snapshots, computing the strategy once. The outcome is NaN when
+// following the strategy had lost everything at the start of those days.
+func latestActionAndOutcome(st strategy.Strategy, data *internal.Series[*asset.Snapshot], days int) (string, float64) {
+	// The actions and outcomes must be drained together.
+	actions, outcomes := strategy.ComputeWithOutcome(st, data.Chan())
+	actionSlice := make(chan []strategy.Action, 1)
+	go func() {
+		actionSlice <- helper.ChanToSlice(actions)
+	}()
+	outcomeSlice := helper.ChanToSlice(outcomes)
+
+	denormalized := helper.ChanToSlice(strategy.DenormalizeActions(helper.SliceToChan(<-actionSlice)))
+	action := mkActionString(denormalized[len(denormalized)-1])
+
+	last := outcomeSlice[len(outcomeSlice)-1]
+	base := outcomeSlice[max(0, len(outcomeSlice)-1-days)]
+	if 1+base == 0 {
+		return action, math.NaN()
 	}
-	if st.Name() == "Awesome Oscillator Strategy" {
-		if datalen < momentum.NewAwesomeOscillatorStrategy().AwesomeOscillator.LongSma.Period {
-			fmt.Println(msg)
-			return true
+	return action, (1+last)/(1+base) - 1
+}
+
+// indicatorValues returns the last value of each numeric column of the strategy's report.
+func indicatorValues(st strategy.Strategy, data *internal.Series[*asset.Snapshot]) map[string]float64 {
+	report := st.Report(data.Chan())
+	values := make(map[string]float64)
+	internal.ReadReport(report, func(_ time.Time, row []string) {
+		for i, column := range report.Columns {
+			if column.Type() != "number" {
+				continue
+			}
+			if value, ok := parseIndicator(row[i]); ok {
+				values[column.Name()] = value
+			}
 		}
+	})
+	return values
+}
+
+// parseIndicator parses a numeric report value. The NaN and infinite values
+// that indicators produce before they have enough data are rejected, as JSON
+// cannot hold them.
+func parseIndicator(value string) (float64, bool) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
 	}
-	if st.Name() == "Bollinger Bands Strategy" {
-		if datalen < volatility.NewBollingerBandsStrategy().BollingerBands.IdlePeriod() {
-			fmt.Println(msg)
-			return true
+	return v, true
+}
+
+// printChanges prints the signals of the run whose action changed since the previous run.
+func printChanges(ledger *app.SignalLedger, runAt time.Time) error {
+	changes, err := ledger.ChangedIn(runAt)
+	if err != nil {
+		slog.Error("reading signal ledger", "err", err)
+		return err
+	}
+	for _, change := range changes {
+		previous := "NEW"
+		if change.Previous != nil {
+			previous = change.Previous.Action
 		}
+		fmt.Println("Changed:", change.Current.Asset, "strategy:", change.Current.Strategy, previous, "->", change.Current.Action)
+	}
+	return nil
+}
+
+// notEnoughData returns true, and logs it, when the asset has too few
+// snapshots for the strategy to get past its warm-up period.
+func notEnoughData(log *slog.Logger, st strategy.Strategy, datalen int) bool {
+	if warmup.HasEnoughData(st, datalen) {
+		return false
 	}
-	return false
+	log.Info("ignoring strategy due to insufficient data", "snapshots", datalen, "needed", warmup.IdlePeriod(st)+1)
+	return true
+}
+
+// writeDigest writes the digest of the run to the outdir, linking to the strategies' reports.
+func writeDigest(ledger *app.SignalLedger, runAt time.Time, outdir string) error {
+	digest, err := app.NewSignalDigestFor(ledger, runAt)
+	if err != nil {
+		slog.Error("reading signal ledger", "err", err)
+		return err
+	}
+	err = digest.WriteToFiles(outdir)
+	if err != nil {
+		slog.Error("writing digest", "err", err)
+		return err
+	}
+	return nil
 }
 
 // mkActionString returns one of BUY, SELL or HOLD for a given strategy.Action
@@ -149,20 +290,3 @@ func mkActionString(action strategy.Action) string {
 	}
 	return "HOLD"
 }
-
-// setActionFile replaces any existing action file with a new one.
-// It removes any existing BUY, SELL or HOLD files.
-// Arguments are assumed to be safe for forming part of a filename.
-func setActionFile(actionString string, assetName string, strategyName string) {
-	filePath := func(act string) string {
-		return fmt.Sprintf("%s/%s--%s--%s.txt", reportdir, assetName, strategyName, act)
-	}
-	// Start by removing any existing BUY, SELL or HOLD files.
-	for _, act := range []string{"BUY", "SELL", "HOLD"} {
-		path := filePath(act)
-		os.Remove(path)
-	}
-	newfile := filePath(actionString)
-	fd, _ := os.Create(newfile)
-	defer fd.Close()
-}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestParseIndicator(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected float64
+		ok       bool
+	}{
+		{"1.5", 1.5, true},
+		{"-20", -20, true},
+		{"NaN", 0, false},
+		{"+Inf", 0, false},
+		{"-Inf", 0, false},
+		{"", 0, false},
+		{"abc", 0, false},
+	}
+	for _, test := range tests {
+		value, ok := parseIndicator(test.value)
+		if value != test.expected || ok != test.ok {
+			t.Fatalf("%q: actual %g, %v expected %g, %v", test.value, value, ok, test.expected, test.ok)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"net/smtp"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vextasy/strategise/app"
+)
+
+// notifyOptions are the flags of the commands that send notifications.
+type notifyOptions struct {
+	specs    []string
+	template string
+}
+
+// register adds the notification flags to the flag set.
+func (n *notifyOptions) register(fs *flag.FlagSet) {
+	fs.Func("notify", "send changes to BUY or SELL to `sink`, one of smtp://[user:password@]host:port?from=address&to=address,...,"+
+		" http(s)://webhook-url or exec:command [args]; may be repeated", func(spec string) error {
+		n.specs = append(n.specs, spec)
+		return nil
+	})
+	fs.StringVar(&n.template, "notify-template", "", "text/template file defining the \"subject\" and \"body\" of notifications")
+}
+
+// notifiers returns a notifier for each sink, each remembering what it has
+// sent in the output directory so that no alert is sent twice.
+func (n *notifyOptions) notifiers(outdir string) ([]app.Notifier, error) {
+	t := app.NewNotificationTemplate()
+	if n.template != "" {
+		var err error
+		if t, err = app.NewNotificationTemplateFromFile(n.template); err != nil {
+			return nil, err
+		}
+	}
+
+	var notifiers []app.Notifier
+	for _, spec := range n.specs {
+		notifier, err := newNotifier(spec, t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -notify %q: %w", spec, err)
+		}
+		// The sent alerts are kept under a hash of the spec as it may hold a password.
+		h := fnv.New64a()
+		h.Write([]byte(spec))
+		path := filepath.Join(outdir, fmt.Sprintf("notified-%016x.json", h.Sum64()))
+		notifiers = append(notifiers, app.NewDedupNotifier(notifier, path))
+	}
+	return notifiers, nil
+}
+
+// newNotifier returns the notifier for a -notify sink.
+func newNotifier(spec string, t *app.NotificationTemplate) (app.Notifier, error) {
+	if command, ok := strings.CutPrefix(spec, "exec:"); ok {
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("no command")
+		}
+		c := app.NewCommandNotifier(fields[0], fields[1:]...)
+		c.Template = t
+		return c, nil
+	}
+
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "smtp":
+		query := u.Query()
+		from := query.Get("from")
+		to := strings.Split(query.Get("to"), ",")
+		if from == "" || query.Get("to") == "" {
+			return nil, fmt.Errorf("from and to addresses are needed")
+		}
+		var auth smtp.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = smtp.PlainAuth("", u.User.Username(), password, u.Hostname())
+		}
+		s := app.NewSmtpNotifier(u.Host, auth, from, to...)
+		s.Template = t
+		return s, nil
+	case "http", "https":
+		w := app.NewWebhookNotifier(spec)
+		w.Template = t
+		return w, nil
+	}
+	return nil, fmt.Errorf("unknown sink")
+}
+
+// notify sends the BUY and SELL signals that changed in the run to each
+// notifier, and says which failed.
+func notify(ctx context.Context, notifiers []app.Notifier, ledger *app.SignalLedger, runAt time.Time) error {
+	if len(notifiers) == 0 {
+		return nil
+	}
+	changes, err := ledger.ChangedIn(runAt)
+	if err != nil {
+		slog.Error("reading signal ledger", "err", err)
+		return err
+	}
+	n := app.NewNotification(runAt, changes)
+
+	var failed error
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(ctx, n); err != nil {
+			slog.Error("notifying", "err", err)
+			failed = err
+		}
+	}
+	return failed
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/strategy"
+
+	"github.com/vextasy/strategise/app"
+	"github.com/vextasy/strategise/domain"
+	"github.com/vextasy/strategise/internal"
+)
+
+// options are the flags shared by all commands.
+type options struct {
+	data     string
+	outdir   string
+	asset    string
+	strategy string
+	from     string
+	to       string
+	dryRun   bool
+	workers  int
+
+	currency      string
+	retired       string
+	ticker        string
+	updatedWithin string
+	staleFor      string
+
+	log *internal.LogFlags
+}
+
+// newFlagSet returns the flag set of a command with the shared options registered.
+func newFlagSet(name string) (*flag.FlagSet, *options) {
+	o := &options{}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&o.data, "data", datadir+"/portfolio.xml", "Portfolio Performance XML file")
+	fs.StringVar(&o.outdir, "out", reportdir, "output directory")
+	fs.StringVar(&o.asset, "asset", "", "only assets whose name matches this glob, or regular expression when prefixed with re:")
+	fs.StringVar(&o.strategy, "strategy", "", "only strategies whose name matches this glob, or regular expression when prefixed with re:")
+	fs.StringVar(&o.from, "from", "", "ignore prices before this date (2006-01-02)")
+	fs.StringVar(&o.to, "to", "", "ignore prices after this date (2006-01-02)")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "show what would be done without doing it")
+	fs.IntVar(&o.workers, "workers", runtime.NumCPU(), "number of assets evaluated in parallel")
+	fs.StringVar(&o.currency, "currency", "", "only securities in these comma separated currencies")
+	fs.StringVar(&o.retired, "retired", "exclude", "retired securities: exclude, include or only")
+	fs.StringVar(&o.ticker, "ticker", "any", "securities by ticker symbol: any, with or without")
+	fs.StringVar(&o.updatedWithin, "updated-within", "", "only securities whose prices were updated within this long, such as 7d or 12h")
+	fs.StringVar(&o.staleFor, "stale-for", "", "only securities whose prices have not been updated for this long, such as 30d")
+	o.log = internal.NewLogFlags(fs)
+	return fs, o
+}
+
+// parse parses the command's arguments and checks the options, reporting
+// whether the command should go ahead and, if not, the exit code.
+func parse(fs *flag.FlagSet, o *options, args []string) (bool, int) {
+	err := fs.Parse(args)
+	if err == flag.ErrHelp {
+		return false, exitOK
+	}
+	if err != nil {
+		return false, exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Unexpected arguments:", strings.Join(fs.Args(), " "))
+		return false, exitUsage
+	}
+	if err := o.check(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false, exitUsage
+	}
+	slog.SetDefault(o.log.NewLogger(os.Stderr))
+	return true, exitOK
+}
+
+// check returns an error describing the first invalid option.
+func (o *options) check() error {
+	if err := o.log.Check(); err != nil {
+		return err
+	}
+	if _, err := parseDate(o.from); err != nil {
+		return fmt.Errorf("invalid -from date: %w", err)
+	}
+	if _, err := parseDate(o.to); err != nil {
+		return fmt.Errorf("invalid -to date: %w", err)
+	}
+	if _, err := newPattern(o.asset); err != nil {
+		return fmt.Errorf("invalid -asset pattern: %w", err)
+	}
+	if _, err := newPattern(o.strategy); err != nil {
+		return fmt.Errorf("invalid -strategy pattern: %w", err)
+	}
+	if _, err := o.securityFilter(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// securityFilter returns the filter the security flags describe.
+func (o *options) securityFilter() (domain.SecurityFilter, error) {
+	var f domain.SecurityFilter
+	for _, currency := range strings.Split(o.currency, ",") {
+		if currency = strings.TrimSpace(currency); currency != "" {
+			f.Currencies = append(f.Currencies, currency)
+		}
+	}
+
+	switch o.retired {
+	case "exclude":
+		f.Retired = domain.ExcludeRetired
+	case "include":
+		f.Retired = domain.IncludeRetired
+	case "only":
+		f.Retired = domain.OnlyRetired
+	default:
+		return f, fmt.Errorf("invalid -retired %q: expected exclude, include or only", o.retired)
+	}
+
+	switch o.ticker {
+	case "any":
+		f.Ticker = domain.AnyTicker
+	case "with":
+		f.Ticker = domain.WithTicker
+	case "without":
+		f.Ticker = domain.WithoutTicker
+	default:
+		return f, fmt.Errorf("invalid -ticker %q: expected any, with or without", o.ticker)
+	}
+
+	var err error
+	if f.UpdatedWithin, err = parseAge(o.updatedWithin); err != nil {
+		return f, fmt.Errorf("invalid -updated-within: %w", err)
+	}
+	if f.StaleFor, err = parseAge(o.staleFor); err != nil {
+		return f, fmt.Errorf("invalid -stale-for: %w", err)
+	}
+	return f, nil
+}
+
+// load reads the portfolio and selects the assets and strategies matching the options.
+func (o *options) load() (domain.Repository, []string, []strategy.Strategy, error) {
+	r, err := o.repository()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	assets, err := o.assets(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return r, assets, o.strategies(), nil
+}
+
+// repository reads the portfolio, restricted to the date range.
+// The options are assumed to have been checked.
+func (o *options) repository() (domain.Repository, error) {
+	from, _ := parseDate(o.from)
+	to, _ := parseDate(o.to)
+
+	r, err := app.NewPortfolioPerformanceRepository(o.data)
+	if err != nil {
+		return nil, err
+	}
+	if from.IsZero() && to.IsZero() {
+		return r, nil
+	}
+	return &periodRepository{Repository: r, from: from, to: to}, nil
+}
+
+// assets returns the names of the repository's assets matching the -asset
+// pattern and the security flags.
+func (o *options) assets(r domain.Repository) ([]string, error) {
+	securities, err := o.securities(r)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(securities))
+	for i, security := range securities {
+		names[i] = security.Name
+	}
+	return names, nil
+}
+
+// securities returns the descriptions of the repository's securities matching
+// the -asset pattern and the security flags.
+func (o *options) securities(r domain.Repository) ([]domain.SecurityInfo, error) {
+	p, _ := newPattern(o.asset)
+	f, _ := o.securityFilter()
+	securities, err := r.Securities()
+	if err != nil {
+		return nil, err
+	}
+	var matched []domain.SecurityInfo
+	for _, security := range f.Filter(securities, time.Now()) {
+		if p.match(security.Name) {
+			matched = append(matched, security)
+		}
+	}
+	return matched, nil
+}
+
+// strategies returns the strategies whose name matches the -strategy pattern.
+func (o *options) strategies() []strategy.Strategy {
+	p, _ := newPattern(o.strategy)
+	var matched []strategy.Strategy
+	for _, st := range allStrategies() {
+		if p.match(st.Name()) {
+			matched = append(matched, st)
+		}
+	}
+	return matched
+}
+
+// parseAge parses a duration such as 12h, or a number of days such as 30d.
+// An empty string is zero.
+func parseAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// parseFormats returns the report formats in a comma separated list.
+func parseFormats(s string) ([]string, error) {
+	var formats []string
+	for _, format := range strings.Split(s, ",") {
+		format = strings.TrimSpace(format)
+		if !slices.Contains(internal.ReportFormats, format) {
+			return nil, fmt.Errorf("invalid -formats: unknown format %q, expected one of %s",
+				format, strings.Join(internal.ReportFormats, ", "))
+		}
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+// parseDate parses a date given on the command line. An empty string is the zero time.
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// pattern matches names by glob, or by regular expression when prefixed with "re:".
+// The empty pattern matches every name.
+type pattern struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+func newPattern(s string) (*pattern, error) {
+	if expr, ok := strings.CutPrefix(s, "re:"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		return &pattern{re: re}, nil
+	}
+	if _, err := filepath.Match(s, ""); err != nil {
+		return nil, err
+	}
+	return &pattern{glob: s}, nil
+}
+
+func (p *pattern) match(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+	if p.glob == "" {
+		return true
+	}
+	matched, _ := filepath.Match(p.glob, name)
+	return matched
+}
+
+// periodRepository restricts the snapshots of a repository to a date range.
+// A zero from or to leaves that end of the range open.
+type periodRepository struct {
+	domain.Repository
+
+	from, to time.Time
+}
+
+// Get returns the snapshots of the asset within the date range.
+func (r *periodRepository) Get(name string) (<-chan *asset.Snapshot, error) {
+	return r.Repository.GetRange(name, r.from, r.to)
+}
+
+// GetSince returns the snapshots of the asset since the date within the date range.
+func (r *periodRepository) GetSince(name string, date time.Time) (<-chan *asset.Snapshot, error) {
+	return r.GetRange(name, date, time.Time{})
+}
+
+// GetRange returns the snapshots of the asset between the dates within the date range.
+func (r *periodRepository) GetRange(name string, from, to time.Time) (<-chan *asset.Snapshot, error) {
+	if from.IsZero() || (!r.from.IsZero() && r.from.After(from)) {
+		from = r.from
+	}
+	if to.IsZero() || (!r.to.IsZero() && r.to.Before(to)) {
+		to = r.to
+	}
+	return r.Repository.GetRange(name, from, to)
+}
+
+// LastDate returns the date of the asset's last snapshot within the date range.
+func (r *periodRepository) LastDate(name string) (time.Time, error) {
+	snapshots, err := r.Get(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var last time.Time
+	for snapshot := range snapshots {
+		last = snapshot.Date
+	}
+	if last.IsZero() {
+		return last, fmt.Errorf("%w: %s", app.ErrEmptyAsset, name)
+	}
+	return last, nil
+}
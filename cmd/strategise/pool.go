@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/strategy"
+
+	"github.com/vextasy/strategise/domain"
+	"github.com/vextasy/strategise/internal"
+)
+
+// task is the work done for one strategy on one asset.
+// Anything logged to log is written once all earlier assets have been written.
+// A returned error counts as a failure of the run.
+type task func(log *slog.Logger, st strategy.Strategy, assetName string, snapshots *internal.Series[*asset.Snapshot]) error
+
+// failure is a task that returned an error. The strategy is empty when the
+// asset itself could not be read.
+type failure struct {
+	asset    string
+	strategy string
+	err      error
+}
+
+// assetResult is the buffered log of all strategies for one asset.
+type assetResult struct {
+	index    int
+	output   bytes.Buffer
+	failures []failure
+}
+
+// runPool loads each asset once and runs the task for every strategy on it,
+// using at most o.workers assets at a time. The tasks' logs are written to
+// out in asset order regardless of completion order, and progress is logged.
+// It returns the failed tasks, counting an asset that cannot be read as one
+// failure. It stops starting new assets once ctx is cancelled and returns
+// ctx.Err().
+func runPool(ctx context.Context, o *options, r domain.Repository, assets []string, strategies []strategy.Strategy, out io.Writer, run task) ([]failure, error) {
+	jobs := make(chan int)
+	results := make(chan *assetResult)
+
+	var wg sync.WaitGroup
+	for w := 0; w < max(1, o.workers); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				result := &assetResult{index: index}
+				log := o.log.NewLogger(&result.output).With("asset", assets[index])
+				runAsset(ctx, log, r, assets[index], strategies, run, result)
+				results <- result
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for index := range assets {
+			select {
+			case jobs <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Hold back results that complete early until their predecessors are written.
+	pending := make(map[int]*assetResult)
+	next := 0
+	var failures []failure
+	for result := range results {
+		pending[result.index] = result
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			out.Write(ready.output.Bytes())
+			failures = append(failures, ready.failures...)
+			next++
+			slog.Info("progress", "assets", next, "of", len(assets))
+		}
+	}
+
+	return failures, ctx.Err()
+}
+
+// runAsset loads the asset and runs the task for each strategy on it,
+// recording the failures in the result.
+func runAsset(ctx context.Context, log *slog.Logger, r domain.Repository, assetName string, strategies []strategy.Strategy, run task, result *assetResult) {
+	snapshots, err := r.Get(assetName)
+	if err != nil {
+		log.Error("reading asset snapshots", "err", err)
+		result.failures = append(result.failures, failure{asset: assetName, err: err})
+		return
+	}
+	series := internal.NewSeriesFromChan(snapshots)
+
+	for _, st := range strategies {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := run(log.With("strategy", st.Name()), st, assetName, series); err != nil {
+			result.failures = append(result.failures, failure{asset: assetName, strategy: st.Name(), err: err})
+		}
+	}
+}
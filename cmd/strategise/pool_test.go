@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/strategy"
+
+	"github.com/vextasy/strategise/internal"
+)
+
+func TestRunPool(t *testing.T) {
+	names := []string{"A", "B", "C", "D", "E", "F", "G", "H"}
+	r := newTestRepository(10, names...)
+	o := newTestOptions(t, "report", "portfolio.xml", "-workers", "4")
+	captureLog(t)
+
+	// Earlier assets take longer, so they complete after later ones.
+	assets := append(names[:4:4], "Missing")
+	assets = append(assets, names[4:]...)
+	run := func(log *slog.Logger, st strategy.Strategy, assetName string, snapshots *internal.Series[*asset.Snapshot]) error {
+		time.Sleep(time.Duration(len(names)-slices.Index(names, assetName)) * time.Millisecond)
+		log.Info("ran")
+		if assetName == "C" {
+			return errors.New("broken")
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	failures, err := runPool(context.Background(), o, r, assets, o.strategies(), &out, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The logs are in asset order whatever order the assets complete in.
+	var expected []string
+	for _, name := range assets {
+		if name == "Missing" {
+			expected = append(expected, "asset=Missing")
+			continue
+		}
+		expected = append(expected, fmt.Sprintf("asset=%s strategy=\"%s\"", name, testStrategy))
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("actual output\n%s\nexpected %d lines", &out, len(expected))
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, expected[i]) {
+			t.Fatalf("actual line %d %q expected %s in it", i, line, expected[i])
+		}
+	}
+
+	if len(failures) != 2 {
+		t.Fatalf("actual failures %+v expected two", failures)
+	}
+	if f := failures[0]; f.asset != "C" || f.strategy != testStrategy || f.err.Error() != "broken" {
+		t.Fatalf("actual failure %+v expected C to fail", f)
+	}
+	if f := failures[1]; f.asset != "Missing" || f.strategy != "" || !errors.Is(f.err, asset.ErrRepositoryAssetNotFound) {
+		t.Fatalf("actual failure %+v expected Missing not to be read", f)
+	}
+}
+
+func TestRunPoolCancelled(t *testing.T) {
+	r := newTestRepository(10, "A", "B")
+	o := newTestOptions(t, "report", "portfolio.xml", "-workers", "1")
+	captureLog(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ran := 0
+	run := func(log *slog.Logger, st strategy.Strategy, assetName string, snapshots *internal.Series[*asset.Snapshot]) error {
+		ran++
+		return nil
+	}
+
+	_, err := runPool(ctx, o, r, []string{"A", "B"}, o.strategies(), &bytes.Buffer{}, run)
+	if !errors.Is(err, context.Canceled) || ran != 0 {
+		t.Fatalf("actual error %v after %d tasks expected %v before any", err, ran, context.Canceled)
+	}
+}
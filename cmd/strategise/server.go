@@ -0,0 +1,507 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+
+	"github.com/vextasy/strategise/analysis"
+	"github.com/vextasy/strategise/domain"
+	"github.com/vextasy/strategise/internal"
+	"github.com/vextasy/strategise/strategy/warmup"
+)
+
+// server serves the portfolio's assets, signals, reports and backtests over
+// HTTP, computing them on demand from the most recently loaded portfolio.
+type server struct {
+	options *options
+	open    func() (domain.Repository, error) // Reads the portfolio
+
+	mu         sync.RWMutex
+	repository domain.Repository
+	modTime    time.Time // Modification time of the loaded portfolio file
+}
+
+// newServer returns a server for the portfolio named by the options.
+func newServer(o *options) (*server, error) {
+	return newServerWith(o, o.repository)
+}
+
+// newServerWith returns a server whose portfolio is read by open, initially
+// and whenever the file named by the options changes.
+func newServerWith(o *options, open func() (domain.Repository, error)) (*server, error) {
+	s := &server{options: o, open: open}
+	if _, err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// reload reads the portfolio again if the file has changed since it was last
+// loaded, and returns true if it did. The previous portfolio is kept on error.
+func (s *server) reload() (bool, error) {
+	info, err := os.Stat(s.options.data)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	unchanged := info.ModTime().Equal(s.modTime)
+	s.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	r, err := s.open()
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.repository = r
+	s.modTime = info.ModTime()
+	s.mu.Unlock()
+	return true, nil
+}
+
+// watch reloads the portfolio whenever the file changes, checking every
+// interval until ctx is cancelled.
+func (s *server) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := s.reload()
+			if err != nil {
+				slog.Error("reloading portfolio", "err", err)
+			} else if reloaded {
+				slog.Info("reloaded portfolio", "path", s.options.data)
+			}
+		}
+	}
+}
+
+// current returns the most recently loaded portfolio.
+func (s *server) current() domain.Repository {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.repository
+}
+
+// handler returns the routes of the dashboard and the JSON API.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleDashboard)
+	mux.HandleFunc("GET /assets", s.handleAssets)
+	mux.HandleFunc("GET /assets/{id}/signals", s.handleSignals)
+	mux.HandleFunc("GET /assets/{id}/report", s.handleReport)
+	mux.HandleFunc("GET /strategies", s.handleStrategies)
+	mux.HandleFunc("GET /backtest", s.handleBacktest)
+	return mux
+}
+
+// assetInfo describes an asset in the /assets response.
+type assetInfo struct {
+	ID        string              `json:"id"`
+	Security  domain.SecurityInfo `json:"security"`
+	Shares    float64             `json:"shares"` // Shares held across all portfolios
+	Prices    int                 `json:"prices"`
+	LastDate  time.Time           `json:"lastDate"`
+	LastPrice float64             `json:"lastPrice"`
+}
+
+// handleAssets lists the assets selected by the -asset option.
+func (s *server) handleAssets(w http.ResponseWriter, req *http.Request) {
+	r := s.current()
+	names, err := s.options.assets(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	positions, err := r.Positions()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	shares := make(map[string]float64, len(positions))
+	for _, position := range positions {
+		shares[position.Security] = position.Shares
+	}
+
+	assets := make([]assetInfo, 0, len(names))
+	for _, name := range names {
+		security, err := r.Security(name)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		snapshots, err := r.Get(name)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		info := assetInfo{ID: name, Security: security, Shares: shares[name]}
+		for snapshot := range snapshots {
+			info.Prices++
+			info.LastDate = snapshot.Date
+			info.LastPrice = snapshot.Close
+		}
+		assets = append(assets, info)
+	}
+	writeJSON(w, http.StatusOK, assets)
+}
+
+// handleSignals returns the latest signal of each selected strategy for the
+// asset. Strategies without enough data for the asset are left out.
+func (s *server) handleSignals(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+	data, err := s.snapshots(id)
+	if err != nil {
+		writeAssetError(w, err)
+		return
+	}
+	strategies, err := s.strategies(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	runAt := time.Now()
+	signals := make([]domain.Signal, 0, len(strategies))
+	for _, st := range strategies {
+		if warmup.HasEnoughData(st, data.Len()) {
+			signals = append(signals, computeSignal(st, id, data, runAt))
+		}
+	}
+	writeJSON(w, http.StatusOK, signals)
+}
+
+// handleReport writes the report of the strategy named by the strategy
+// parameter for the asset, as HTML or in the format parameter's format,
+// titled with the description of the asset's security as runReport does.
+func (s *server) handleReport(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+	data, err := s.snapshots(id)
+	if err != nil {
+		writeAssetError(w, err)
+		return
+	}
+
+	name := req.URL.Query().Get("strategy")
+	var st strategy.Strategy
+	for _, candidate := range allStrategies() {
+		if candidate.Name() == name {
+			st = candidate
+		}
+	}
+	if st == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown strategy %q", name))
+		return
+	}
+	if !warmup.HasEnoughData(st, data.Len()) {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Errorf("%s needs %d prices, %s has %d",
+			st.Name(), warmup.IdlePeriod(st)+1, id, data.Len()))
+		return
+	}
+
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = "html"
+	}
+	contentType, ok := map[string]string{
+		"html": "text/html; charset=utf-8",
+		"csv":  "text/csv; charset=utf-8",
+		"json": "application/json",
+	}[format]
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown report format %q", format))
+		return
+	}
+
+	security, err := s.current().Security(id)
+	if err != nil {
+		writeAssetError(w, err)
+		return
+	}
+	report := st.Report(data.Chan())
+	report.Title = st.Name() + " for " + security.String()
+
+	w.Header().Set("Content-Type", contentType)
+	if err := internal.WriteReport(report, format, w); err != nil {
+		slog.Error("writing report", "asset", id, "strategy", st.Name(), "err", err)
+	}
+}
+
+// strategyInfo describes a strategy in the /strategies response.
+type strategyInfo struct {
+	Name   string `json:"name"`
+	WarmUp int    `json:"warmUp"`
+}
+
+// handleStrategies lists the selected strategies.
+func (s *server) handleStrategies(w http.ResponseWriter, req *http.Request) {
+	strategies, err := s.strategies(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	infos := make([]strategyInfo, len(strategies))
+	for i, st := range strategies {
+		infos[i] = strategyInfo{Name: st.Name(), WarmUp: warmup.IdlePeriod(st)}
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// backtestResult is one asset and strategy in the /backtest response.
+type backtestResult struct {
+	Asset       string  `json:"asset"`
+	Strategy    string  `json:"strategy"`
+	Return      float64 `json:"return"`
+	BuyAndHold  float64 `json:"buyAndHold"`
+	MaxDrawdown float64 `json:"maxDrawdown"`
+}
+
+// handleBacktest returns the return and maximum drawdown of following each
+// selected strategy on each selected asset over the last days, alongside the
+// return of holding the asset. The asset and strategy parameters select by
+// pattern, and days defaults to 365.
+func (s *server) handleBacktest(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	days := 365
+	if query.Has("days") {
+		var err error
+		days, err = strconv.Atoi(query.Get("days"))
+		if err != nil || days <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid days %q", query.Get("days")))
+			return
+		}
+	}
+
+	assetPattern, err := newPattern(query.Get("asset"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid asset pattern: %w", err))
+		return
+	}
+	strategies, err := s.strategies(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	r := s.current()
+	names, err := s.options.assets(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	results := []backtestResult{}
+	for _, name := range names {
+		if !assetPattern.match(name) {
+			continue
+		}
+		snapshots, err := r.Get(name)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		snapshotSlice := helper.ChanToSlice(snapshots)
+		start := max(0, len(snapshotSlice)-days)
+		holding := analysis.StrategyReturns(snapshotSlice, []float64{1})[start:]
+
+		for _, st := range strategies {
+			if !warmup.HasEnoughData(st, len(snapshotSlice)) {
+				continue
+			}
+			actions := analysis.ComputeActions(st, snapshotSlice)
+			returns := analysis.StrategyReturns(snapshotSlice, actions)[start:]
+			results = append(results, backtestResult{
+				Asset:       name,
+				Strategy:    st.Name(),
+				Return:      totalReturn(returns),
+				BuyAndHold:  totalReturn(holding),
+				MaxDrawdown: analysis.MaxDrawdown(returns),
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// handleDashboard serves the dashboard page, which uses the JSON API.
+func (s *server) handleDashboard(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, dashboardPage)
+}
+
+// snapshots returns the snapshots of the asset from the current portfolio.
+func (s *server) snapshots(id string) (*internal.Series[*asset.Snapshot], error) {
+	snapshots, err := s.current().Get(id)
+	if err != nil {
+		return nil, err
+	}
+	data := internal.NewSeries(helper.ChanToSlice(snapshots))
+	if data.Len() == 0 {
+		return nil, fmt.Errorf("%s has no prices", id)
+	}
+	return data, nil
+}
+
+// strategies returns the strategies selected by the -strategy option and
+// the strategy pattern parameter.
+func (s *server) strategies(req *http.Request) ([]strategy.Strategy, error) {
+	p, err := newPattern(req.URL.Query().Get("strategy"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid strategy pattern: %w", err)
+	}
+	var matched []strategy.Strategy
+	for _, st := range s.options.strategies() {
+		if p.match(st.Name()) {
+			matched = append(matched, st)
+		}
+	}
+	return matched, nil
+}
+
+// totalReturn compounds the daily returns.
+func totalReturn(returns []float64) float64 {
+	cumulative := analysis.Cumulative(returns)
+	if len(cumulative) == 0 {
+		return 0
+	}
+	return cumulative[len(cumulative)-1] / 100
+}
+
+// writeJSON writes the value as the JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the error as a JSON response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeAssetError writes an error reading an asset, which is not found when
+// the repository does not have it.
+func writeAssetError(w http.ResponseWriter, err error) {
+	if errors.Is(err, asset.ErrRepositoryAssetNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+// dashboardPage lists the assets and shows the selected asset's signals,
+// links to its reports and its backtest results.
+const dashboardPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Strategise</title>
+<style>
+body { font-family: sans-serif; display: flex; gap: 2em; }
+#assets { min-width: 16em; }
+#assets li { cursor: pointer; list-style: none; padding: 2px 4px; }
+#assets li.selected { background: #ddeeff; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; white-space: nowrap; }
+.BUY { background: #d8f5d8; }
+.SELL { background: #f8d8d8; }
+</style>
+</head>
+<body>
+<div>
+<h2>Assets</h2>
+<input id="filter" type="search" placeholder="Filter assets" autofocus>
+<ul id="assets"></ul>
+</div>
+<div>
+<h2 id="title">Select an asset</h2>
+<div id="signals"></div>
+<div id="backtest"></div>
+</div>
+<script>
+const text = s => String(s).replace(/[&<>"]/g, c => ({"&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;"}[c]));
+const percent = v => (v * 100).toFixed(1) + "%";
+const regexp = s => "re:^" + s.replace(/[.*+?^${}()|[\]\\]/g, "\\$&") + "$";
+
+async function getJSON(url) {
+  const response = await fetch(url);
+  const body = await response.json();
+  if (!response.ok) throw new Error(body.error);
+  return body;
+}
+
+async function showAssets() {
+  const list = document.getElementById("assets");
+  for (const a of await getJSON("/assets")) {
+    const li = document.createElement("li");
+    li.textContent = a.id;
+    li.title = a.prices + " prices to " + a.lastDate.slice(0, 10);
+    li.onclick = () => showAsset(a.id, li);
+    list.appendChild(li);
+  }
+}
+
+async function showAsset(id, li) {
+  document.querySelectorAll("#assets li").forEach(e => e.classList.remove("selected"));
+  li.classList.add("selected");
+  document.getElementById("title").textContent = id;
+  const signals = document.getElementById("signals");
+  const backtest = document.getElementById("backtest");
+  signals.innerHTML = "Computing signals...";
+  backtest.innerHTML = "";
+  const asset = encodeURIComponent(id);
+  try {
+    let html = "<table><tr><th>Strategy</th><th>Action</th><th>Date</th><th>Price</th><th>Data</th></tr>";
+    for (const s of await getJSON("/assets/" + asset + "/signals")) {
+      const report = "/assets/" + asset + "/report?strategy=" + encodeURIComponent(s.strategy);
+      html += "<tr class=\"" + text(s.action) + "\"><td><a href=\"" + text(report) + "\" target=\"_blank\">" + text(s.strategy) + "</a></td><td>" +
+        text(s.action) + "</td><td>" + text(s.date.slice(0, 10)) + "</td><td>" + s.price.toFixed(2) + "</td><td>" +
+        "<a href=\"" + text(report) + "&amp;format=csv\">CSV</a> <a href=\"" + text(report) + "&amp;format=json\" target=\"_blank\">JSON</a></td></tr>";
+    }
+    signals.innerHTML = html + "</table>";
+
+    backtest.innerHTML = "Backtesting...";
+    html = "<h3>Last 365 days</h3><table><tr><th>Strategy</th><th>Return</th><th>Buy and Hold</th><th>Max Drawdown</th></tr>";
+    for (const b of await getJSON("/backtest?asset=" + encodeURIComponent(regexp(id)))) {
+      html += "<tr><td>" + text(b.strategy) + "</td><td>" + percent(b.return) + "</td><td>" + percent(b.buyAndHold) + "</td><td>" + percent(b.maxDrawdown) + "</td></tr>";
+    }
+    backtest.innerHTML = html + "</table>";
+  } catch (e) {
+    signals.textContent = "Error: " + e.message;
+  }
+}
+
+document.getElementById("filter").addEventListener("input", e => {
+  const filter = e.target.value.toLowerCase();
+  document.querySelectorAll("#assets li").forEach(li => {
+    li.hidden = !li.textContent.toLowerCase().includes(filter);
+  });
+});
+
+showAssets();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+
+	"github.com/vextasy/strategise/app"
+	"github.com/vextasy/strategise/domain"
+)
+
+// testStrategy is the strategy the tests select, one of this repository's own.
+const testStrategy = "Bold MACD Strategy (12,26,9)"
+
+// newTestSnapshots returns count daily snapshots of a price oscillating around 100.
+func newTestSnapshots(count int) []*asset.Snapshot {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := make([]*asset.Snapshot, count)
+	for i := range snapshots {
+		price := 100 + 10*math.Sin(float64(i)/8)
+		snapshots[i] = &asset.Snapshot{
+			Date:  start.AddDate(0, 0, i),
+			Open:  price,
+			High:  price + 1,
+			Low:   price - 1,
+			Close: price,
+		}
+	}
+	return snapshots
+}
+
+// newTestRepository returns a repository holding the named assets, each with count snapshots.
+func newTestRepository(count int, names ...string) *app.MemoryRepository {
+	r := app.NewMemoryRepository()
+	for _, name := range names {
+		r.Add(domain.SecurityInfo{Name: name, Currency: "GBP"}, newTestSnapshots(count)...)
+	}
+	return r
+}
+
+// newTestOptions returns the options of a command parsed from args, with the
+// portfolio file at data and only the test strategy selected.
+func newTestOptions(t *testing.T, name, data string, args ...string) *options {
+	t.Helper()
+	fs, o := newFlagSet(name)
+	args = append([]string{"-data", data, "-strategy", "re:^" + regexp.QuoteMeta(testStrategy) + "$"}, args...)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.check(); err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+// newTestServer returns a server over the repository, whose portfolio file is
+// a placeholder in a temporary directory.
+func newTestServer(t *testing.T, r domain.Repository) *server {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "portfolio.xml")
+	if err := os.WriteFile(path, []byte("<client/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := newServerWith(newTestOptions(t, "serve", path), func() (domain.Repository, error) {
+		return r, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// get requests the path from the server's handler, decoding a JSON response into v if it is not nil.
+func get(t *testing.T, s *server, path string, v any) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if v != nil && w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+	return w
+}
+
+func TestServerAssets(t *testing.T) {
+	r := newTestRepository(100, "Alpha", "Beta")
+	r.Add(domain.SecurityInfo{Name: "Retired", Retired: true}, newTestSnapshots(100)...)
+	r.SetPosition("Alpha", 12.5)
+	s := newTestServer(t, r)
+
+	var assets []assetInfo
+	w := get(t, s, "/assets", &assets)
+	if w.Code != http.StatusOK {
+		t.Fatalf("actual status %d expected %d", w.Code, http.StatusOK)
+	}
+	if len(assets) != 2 || assets[0].ID != "Alpha" || assets[1].ID != "Beta" {
+		t.Fatalf("actual assets %+v expected Alpha and Beta", assets)
+	}
+	if assets[0].Shares != 12.5 || assets[0].Prices != 100 || assets[0].Security.Currency != "GBP" {
+		t.Fatalf("actual Alpha %+v", assets[0])
+	}
+	if assets[1].Shares != 0 {
+		t.Fatalf("actual Beta shares %g expected 0", assets[1].Shares)
+	}
+}
+
+func TestServerSignals(t *testing.T) {
+	s := newTestServer(t, newTestRepository(100, "Alpha"))
+
+	var signals []domain.Signal
+	w := get(t, s, "/assets/Alpha/signals", &signals)
+	if w.Code != http.StatusOK {
+		t.Fatalf("actual status %d expected %d", w.Code, http.StatusOK)
+	}
+	if len(signals) != 1 || signals[0].Strategy != testStrategy || signals[0].Asset != "Alpha" {
+		t.Fatalf("actual signals %+v", signals)
+	}
+	if !signals[0].Date.Equal(newTestSnapshots(100)[99].Date) {
+		t.Fatalf("actual date %v expected the last snapshot's", signals[0].Date)
+	}
+
+	if w := get(t, s, "/assets/Unknown/signals", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown asset: actual status %d expected %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestServerSignalsNotEnoughData(t *testing.T) {
+	s := newTestServer(t, newTestRepository(5, "Alpha"))
+
+	var signals []domain.Signal
+	if w := get(t, s, "/assets/Alpha/signals", &signals); w.Code != http.StatusOK {
+		t.Fatalf("actual status %d expected %d", w.Code, http.StatusOK)
+	}
+	if len(signals) != 0 {
+		t.Fatalf("actual signals %+v expected none", signals)
+	}
+}
+
+func TestServerReport(t *testing.T) {
+	s := newTestServer(t, newTestRepository(100, "Alpha"))
+	report := "/assets/Alpha/report?strategy=" + url.QueryEscape(testStrategy)
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+	}{
+		{report, http.StatusOK, "text/html; charset=utf-8"},
+		{report + "&format=csv", http.StatusOK, "text/csv; charset=utf-8"},
+		{report + "&format=json", http.StatusOK, "application/json"},
+		{report + "&format=xml", http.StatusBadRequest, "application/json"},
+		{"/assets/Alpha/report?strategy=Unknown", http.StatusNotFound, "application/json"},
+		{"/assets/Unknown/report?strategy=" + url.QueryEscape(testStrategy), http.StatusNotFound, "application/json"},
+	}
+	for _, test := range tests {
+		w := get(t, s, test.path, nil)
+		if w.Code != test.status {
+			t.Errorf("%s: actual status %d expected %d", test.path, w.Code, test.status)
+		}
+		if actual := w.Header().Get("Content-Type"); actual != test.contentType {
+			t.Errorf("%s: actual content type %q expected %q", test.path, actual, test.contentType)
+		}
+	}
+
+	w := get(t, s, report+"&format=csv", nil)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 101 {
+		t.Fatalf("actual %d CSV lines expected a header and 100 rows", len(lines))
+	}
+
+	var doc struct {
+		Title string `json:"title"`
+	}
+	get(t, s, report+"&format=json", &doc)
+	if title := testStrategy + " for Alpha (GBP, never updated)"; doc.Title != title {
+		t.Fatalf("actual title %q expected %q", doc.Title, title)
+	}
+}
+
+func TestServerStrategies(t *testing.T) {
+	s := newTestServer(t, newTestRepository(100, "Alpha"))
+
+	var strategies []strategyInfo
+	if w := get(t, s, "/strategies", &strategies); w.Code != http.StatusOK {
+		t.Fatalf("actual status %d expected %d", w.Code, http.StatusOK)
+	}
+	if len(strategies) != 1 || strategies[0].Name != testStrategy || strategies[0].WarmUp <= 0 {
+		t.Fatalf("actual strategies %+v", strategies)
+	}
+
+	if w := get(t, s, "/strategies?strategy=re:(", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad pattern: actual status %d expected %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestServerBacktest(t *testing.T) {
+	s := newTestServer(t, newTestRepository(100, "Alpha", "Beta"))
+
+	var results []backtestResult
+	if w := get(t, s, "/backtest?days=50&asset=Alpha", &results); w.Code != http.StatusOK {
+		t.Fatalf("actual status %d expected %d", w.Code, http.StatusOK)
+	}
+	if len(results) != 1 || results[0].Asset != "Alpha" || results[0].Strategy != testStrategy {
+		t.Fatalf("actual results %+v", results)
+	}
+
+	for _, days := range []string{"abc", "0", "-1"} {
+		if w := get(t, s, "/backtest?days="+days, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("days %s: actual status %d expected %d", days, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestServerReload(t *testing.T) {
+	repositories := []domain.Repository{
+		newTestRepository(100, "Alpha"),
+		newTestRepository(100, "Alpha", "Beta"),
+	}
+	opened := 0
+
+	path := filepath.Join(t.TempDir(), "portfolio.xml")
+	if err := os.WriteFile(path, []byte("<client/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := newServerWith(newTestOptions(t, "serve", path), func() (domain.Repository, error) {
+		r := repositories[min(opened, len(repositories)-1)]
+		opened++
+		return r, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded, err := s.reload(); err != nil || reloaded {
+		t.Fatalf("unchanged file: actual reloaded %v, %v expected false", reloaded, err)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := s.reload(); err != nil || !reloaded {
+		t.Fatalf("changed file: actual reloaded %v, %v expected true", reloaded, err)
+	}
+
+	var assets []assetInfo
+	get(t, s, "/assets", &assets)
+	if len(assets) != 2 {
+		t.Fatalf("actual %d assets after reload expected 2", len(assets))
+	}
+
+	// The previous portfolio is kept when the file cannot be read.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.reload(); err == nil {
+		t.Fatal("missing file: expected an error")
+	}
+	get(t, s, "/assets", &assets)
+	if len(assets) != 2 {
+		t.Fatalf("actual %d assets after a failed reload expected 2", len(assets))
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/cinar/indicator/v2/strategy/compound"
+	"github.com/cinar/indicator/v2/strategy/momentum"
+	"github.com/cinar/indicator/v2/strategy/trend"
+	"github.com/cinar/indicator/v2/strategy/volatility"
+
+	"github.com/vextasy/strategise/strategy/combined"
+	"github.com/vextasy/strategise/strategy/decorator"
+	"github.com/vextasy/strategise/strategy/regime"
+	alt_trend "github.com/vextasy/strategise/strategy/trend"
+	alt_volatility "github.com/vextasy/strategise/strategy/volatility"
+)
+
+// allStrategies returns the strategies that the commands choose from.
+// The rotation strategy ranks all assets at once, so it has its own command.
+func allStrategies() []strategy.Strategy {
+	return []strategy.Strategy{
+		combined.NewWishfulThinkingStrategyWith(30, 70),
+		combined.NewWishfulThinkingStrategyWithUlcerIndex(30, 70, 5, 10),
+		combined.NewAwesomeMbuStrategyWith(40, 60),
+		combined.NewAwesomeMbuStrategyWithUlcerIndex(40, 60, 5, 10),
+		combined.NewAwesomeMbuStrategyWithCombination(40, 60, combined.OrCombination,
+			combined.MacdComponent,
+			combined.AwesomeOscillatorComponent,
+			combined.RsiComponent),
+		combined.NewAwesomeMbuStrategyWithCombination(40, 60, combined.RsiFilterCombination,
+			combined.MacdComponent,
+			combined.AwesomeOscillatorComponent,
+			combined.RsiComponent),
+		alt_trend.NewBoldMacdStrategy(),
+		decorator.NewTrailingStopStrategy(alt_trend.NewBoldMacdStrategy(), 0.1),
+		decorator.NewFilterStrategy(alt_trend.NewBoldMacdStrategy(),
+			decorator.NewPersistenceFilter(2),
+			decorator.NewMinimumHoldFilter(10)),
+		decorator.NewCooldownStrategy(alt_trend.NewBoldMacdStrategy(), 5),
+		alt_volatility.NewUlcerIndexStrategy(),
+		regime.NewSwitchingStrategy(),
+		volatility.NewBollingerBandsStrategy(),
+		////volatility.NewSuperTrendStrategy(),
+		trend.NewMacdStrategy(),
+		trend.NewMacdStrategyWith(5, 35, 5),
+		//trend.NewApoStrategy(),
+		//trend.NewAroonStrategy(),
+		//trend.NewBopStrategy(),
+		////trend.NewCciStrategy(),
+		////trend.NewDemaStrategy(),
+		//trend.NewGoldenCrossStrategy(),
+		////trend.NewKamaStrategy(),
+		//trend.NewKdjStrategy(),
+		trend.NewQstickStrategy(),
+		////trend.NewTrimaStrategy(),
+		////trend.NewTripleMovingAverageCrossoverStrategy(),
+		////trend.NewTrixStrategy(),
+		////trend.NewTsiStrategy(),
+		////trend.NewVwmaStrategy(),
+		momentum.NewRsiStrategy(),
+		momentum.NewRsiStrategyWith(40, 60),
+		//momentum.NewAwesomeOscillatorStrategy(),
+		////momentum.NewStochasticRsiStrategy(),
+		////momentum.NewTripleRsiStrategy(),
+		compound.NewMacdRsiStrategy(),
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"os"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/strategy"
+
+	"github.com/vextasy/strategise/domain"
+	"github.com/vextasy/strategise/internal"
+)
+
+// fileState is what polling a file can see change.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// statFile returns the current state of the file.
+func statFile(path string) (fileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{info.ModTime(), info.Size()}, nil
+}
+
+// waitForChange polls the file every interval until its state differs from
+// last and has then stayed the same for settle, so that a save made in
+// several steps is only seen once it is complete. It returns the new state,
+// or ctx.Err() once ctx is cancelled. A file that cannot be read is treated
+// as still being saved.
+func waitForChange(ctx context.Context, path string, last fileState, interval, settle time.Duration) (fileState, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	candidate := last
+	var changedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-ticker.C:
+		}
+
+		state, err := statFile(path)
+		if err != nil {
+			continue
+		}
+		if state != candidate {
+			candidate = state
+			changedAt = time.Now()
+			continue
+		}
+		if candidate != last && time.Since(changedAt) >= settle {
+			return candidate, nil
+		}
+	}
+}
+
+// fingerprint returns a hash of the asset's price history, which changes
+// whenever a price is added, removed or amended.
+func fingerprint(snapshots <-chan *asset.Snapshot) uint64 {
+	h := fnv.New64a()
+	var b [16]byte
+	for snapshot := range snapshots {
+		binary.LittleEndian.PutUint64(b[:8], uint64(snapshot.Date.Unix()))
+		binary.LittleEndian.PutUint64(b[8:], math.Float64bits(snapshot.Close))
+		h.Write(b[:])
+	}
+	return h.Sum64()
+}
+
+// changedAssets returns the assets whose price history differs from the
+// fingerprints, with their new fingerprints. The fingerprints are left for
+// the caller to update once the changed assets have been dealt with, but
+// assets no longer present are forgotten.
+func changedAssets(r domain.Repository, assets []string, fingerprints map[string]uint64) ([]string, map[string]uint64, error) {
+	present := make(map[string]bool, len(assets))
+	var changed []string
+	updated := make(map[string]uint64)
+	for _, name := range assets {
+		present[name] = true
+		snapshots, err := r.Get(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		f := fingerprint(snapshots)
+		if previous, ok := fingerprints[name]; !ok || previous != f {
+			changed = append(changed, name)
+			updated[name] = f
+		}
+	}
+	for name := range fingerprints {
+		if !present[name] {
+			delete(fingerprints, name)
+		}
+	}
+	return changed, updated, nil
+}
+
+// combineTasks returns a task that runs each of the tasks in turn.
+// It returns the first error, but runs the remaining tasks regardless.
+func combineTasks(tasks ...task) task {
+	return func(log *slog.Logger, st strategy.Strategy, assetName string, data *internal.Series[*asset.Snapshot]) error {
+		var first error
+		for _, run := range tasks {
+			if err := run(log, st, assetName, data); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+)
+
+func TestChangedAssets(t *testing.T) {
+	r := newTestRepository(50, "Alpha", "Beta")
+	fingerprints := make(map[string]uint64)
+
+	changed, updated, err := changedAssets(r, []string{"Alpha", "Beta"}, fingerprints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(changed, []string{"Alpha", "Beta"}) || len(updated) != 2 {
+		t.Fatalf("first run: actual changed %v expected Alpha and Beta", changed)
+	}
+
+	// Until the caller commits them, the same assets are still changed.
+	changed, _, _ = changedAssets(r, []string{"Alpha", "Beta"}, fingerprints)
+	if len(changed) != 2 {
+		t.Fatalf("uncommitted: actual changed %v expected Alpha and Beta", changed)
+	}
+
+	for name, f := range updated {
+		fingerprints[name] = f
+	}
+	changed, _, _ = changedAssets(r, []string{"Alpha", "Beta"}, fingerprints)
+	if len(changed) != 0 {
+		t.Fatalf("committed: actual changed %v expected none", changed)
+	}
+
+	next := newTestSnapshots(51)[50]
+	if err := r.Append("Beta", helper.SliceToChan([]*asset.Snapshot{next})); err != nil {
+		t.Fatal(err)
+	}
+	changed, _, _ = changedAssets(r, []string{"Alpha", "Beta"}, fingerprints)
+	if !slices.Equal(changed, []string{"Beta"}) {
+		t.Fatalf("new price: actual changed %v expected Beta", changed)
+	}
+
+	// An asset no longer selected is forgotten.
+	changedAssets(r, []string{"Beta"}, fingerprints)
+	if _, ok := fingerprints["Alpha"]; ok {
+		t.Fatal("Alpha was not forgotten")
+	}
+}
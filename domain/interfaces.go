@@ -1,13 +1,30 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/cinar/indicator/v2/asset"
 )
 
-type PortfolioPerformanceRepository interface {
-	// Assets returns the names of all assets in the repository.
-	Assets() ([]string, error)
+// Repository is the source of the portfolio's securities, their prices and
+// the positions held in them. Assets are named by their cleaned security
+// names. It is also an asset.Repository so that it can be used with the
+// indicator library's backtests.
+type Repository interface {
+	asset.Repository
+
+	// Securities returns the description of every security, retired or not,
+	// ordered by name.
+	Securities() ([]SecurityInfo, error)
+
+	// Security returns the description of the named security.
+	Security(name string) (SecurityInfo, error)
+
+	// GetRange returns the snapshots of the named asset from one date to
+	// another, inclusive. A zero from or to leaves that end of the range open.
+	GetRange(name string, from, to time.Time) (<-chan *asset.Snapshot, error)
 
-	// Get returns the Snapshots for the given asset.
-	Get(name string) ([]*asset.Snapshot, error)
+	// Positions returns the number of shares held in each security that has
+	// a non-zero position, ordered by security name.
+	Positions() ([]Position, error)
 }
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"encoding/xml"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -13,6 +15,7 @@ type Client struct {
 
 // A Security contains information about a given security within the XML file.
 type Security struct {
+	ID           string    `xml:"id,attr"` // Only in files that refer to objects by id
 	Name         string    `xml:"name"`
 	CurrencyCode string    `xml:"currencyCode"`
 	ISIN         string    `xml:"isin"`
@@ -21,7 +24,56 @@ type Security struct {
 	IsRetired    string    `xml:"isRetired"` // "false" or "true"
 	UpdatedAt    time.Time `xml:"updatedAt"`
 }
+
+// Info returns the description of the security.
+func (s Security) Info() SecurityInfo {
+	return SecurityInfo{
+		Name:      s.Name,
+		ISIN:      s.ISIN,
+		Ticker:    s.TickerSymbol,
+		Currency:  s.CurrencyCode,
+		Retired:   s.IsRetired == "true",
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
 type Price struct {
 	Date  string  `xml:"t,attr"`
 	Value float64 `xml:"v,attr"`
 }
+
+// A SecurityInfo describes a security without its prices.
+type SecurityInfo struct {
+	Name      string    `json:"name"`
+	ISIN      string    `json:"isin,omitempty"`
+	Ticker    string    `json:"ticker,omitempty"`
+	Currency  string    `json:"currency,omitempty"`
+	Retired   bool      `json:"retired"`
+	UpdatedAt time.Time `json:"updatedAt"` // When the prices were last updated
+}
+
+// String describes the security in a line, such as
+// "Alpha_Fund (ALP, GB0001234567, GBP, updated 2024-01-01)".
+func (s SecurityInfo) String() string {
+	var details []string
+	for _, detail := range []string{s.Ticker, s.ISIN, s.Currency} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if s.UpdatedAt.IsZero() {
+		details = append(details, "never updated")
+	} else {
+		details = append(details, "updated "+s.UpdatedAt.Format("2006-01-02"))
+	}
+	if s.Retired {
+		details = append(details, "retired")
+	}
+	return fmt.Sprintf("%s (%s)", s.Name, strings.Join(details, ", "))
+}
+
+// A Position is the number of shares held in a security across all portfolios.
+type Position struct {
+	Security string  `json:"security"`
+	Shares   float64 `json:"shares"`
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// RetiredFilter selects securities by whether they are retired.
+type RetiredFilter int
+
+const (
+	ExcludeRetired RetiredFilter = iota // Only securities that are not retired
+	IncludeRetired                      // Securities whether retired or not
+	OnlyRetired                         // Only retired securities
+)
+
+// TickerFilter selects securities by whether they have a ticker symbol.
+type TickerFilter int
+
+const (
+	AnyTicker     TickerFilter = iota // Securities with or without a ticker
+	WithTicker                        // Only securities with a ticker
+	WithoutTicker                     // Only securities without a ticker
+)
+
+// A SecurityFilter selects securities by their description. The zero filter
+// selects the securities that are not retired, as Assets does.
+type SecurityFilter struct {
+	// Currencies are the currency codes selected, or all when empty.
+	Currencies []string
+
+	Retired RetiredFilter
+	Ticker  TickerFilter
+
+	// UpdatedWithin, when not zero, selects only the securities whose prices
+	// were updated within this long of the time the filter is applied.
+	UpdatedWithin time.Duration
+
+	// StaleFor, when not zero, selects only the securities whose prices have
+	// not been updated for at least this long. A security that has never been
+	// updated is stale.
+	StaleFor time.Duration
+}
+
+// Match reports whether the filter selects the security at time now.
+func (f SecurityFilter) Match(s SecurityInfo, now time.Time) bool {
+	if len(f.Currencies) > 0 && !slices.ContainsFunc(f.Currencies, func(c string) bool {
+		return strings.EqualFold(c, s.Currency)
+	}) {
+		return false
+	}
+
+	switch f.Retired {
+	case ExcludeRetired:
+		if s.Retired {
+			return false
+		}
+	case OnlyRetired:
+		if !s.Retired {
+			return false
+		}
+	}
+
+	switch f.Ticker {
+	case WithTicker:
+		if s.Ticker == "" {
+			return false
+		}
+	case WithoutTicker:
+		if s.Ticker != "" {
+			return false
+		}
+	}
+
+	age := now.Sub(s.UpdatedAt)
+	if f.UpdatedWithin > 0 && (s.UpdatedAt.IsZero() || age > f.UpdatedWithin) {
+		return false
+	}
+	if f.StaleFor > 0 && !s.UpdatedAt.IsZero() && age < f.StaleFor {
+		return false
+	}
+	return true
+}
+
+// Filter returns the securities the filter selects at time now, in the same order.
+func (f SecurityFilter) Filter(securities []SecurityInfo, now time.Time) []SecurityInfo {
+	var selected []SecurityInfo
+	for _, s := range securities {
+		if f.Match(s, now) {
+			selected = append(selected, s)
+		}
+	}
+	return selected
+}
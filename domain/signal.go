@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// A Signal is the action recommended by a strategy for an asset on a given run.
+type Signal struct {
+	RunAt      time.Time          `json:"runAt"`
+	Asset      string             `json:"asset"`
+	Strategy   string             `json:"strategy"`
+	Date       time.Time          `json:"date"`   // Date of the last snapshot
+	Action     string             `json:"action"` // "BUY", "SELL" or "HOLD"
+	Price      float64            `json:"price"`  // Closing price of the last snapshot
+	Indicators map[string]float64 `json:"indicators,omitempty"`
+}
+
+// A SignalChange is a signal whose action differs from the one before it.
+// Previous is nil when the asset and strategy had no earlier signal.
+type SignalChange struct {
+	Previous *Signal
+	Current  Signal
+}
@@ -12,3 +12,14 @@ func CleanFilename(filename string) string {
 	filename = strings.ReplaceAll(filename, "/", "-")
 	return filepath.Clean(filename)
 }
+
+// ReportFilename is the name of the file holding a strategy's HTML report for an asset.
+func ReportFilename(assetName, strategyName string) string {
+	return ReportFilenameFor(assetName, strategyName, "html")
+}
+
+// ReportFilenameFor is the name of the file holding a strategy's report for
+// an asset in the format, which is also the file's extension.
+func ReportFilenameFor(assetName, strategyName, format string) string {
+	return CleanFilename(assetName) + "--" + CleanFilename(strategyName) + "." + format
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// LogFlags are the -log-level and -log-format flags of a command.
+type LogFlags struct {
+	Level  string
+	Format string
+}
+
+// NewLogFlags registers the -log-level and -log-format flags with fs.
+func NewLogFlags(fs *flag.FlagSet) *LogFlags {
+	f := &LogFlags{}
+	fs.StringVar(&f.Level, "log-level", "info", "least severe log messages written: debug, info, warn or error")
+	fs.StringVar(&f.Format, "log-format", "text", "format of log messages: text or json")
+	return f
+}
+
+// Check returns an error describing the first invalid flag.
+func (f *LogFlags) Check() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(f.Level)); err != nil {
+		return fmt.Errorf("invalid -log-level: %w", err)
+	}
+	if f.Format != "text" && f.Format != "json" {
+		return fmt.Errorf("invalid -log-format %q: expected text or json", f.Format)
+	}
+	return nil
+}
+
+// NewLogger returns a logger writing to w at the level and in the format of
+// the flags, which must have been checked.
+func (f *LogFlags) NewLogger(w io.Writer) *slog.Logger {
+	var level slog.Level
+	level.UnmarshalText([]byte(f.Level))
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	if f.Format == "json" {
+		return slog.New(slog.NewJSONHandler(w, handlerOptions))
+	}
+	return slog.New(slog.NewTextHandler(w, handlerOptions))
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cinar/indicator/v2/helper"
+)
+
+// ReportFormats are the formats a report can be written in.
+var ReportFormats = []string{"html", "csv", "json"}
+
+// WriteReport writes the report to w in the format, which is one of ReportFormats.
+// The CSV and JSON formats hold the same columns as the HTML report's charts.
+func WriteReport(report *helper.Report, format string, w io.Writer) error {
+	switch format {
+	case "html":
+		return report.WriteToWriter(w)
+	case "csv":
+		return WriteReportCsv(report, w)
+	case "json":
+		return WriteReportJson(report, w)
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// ReportColumnNames returns a unique name for each of the report's columns.
+// Annotations, which have no name in the report, are called Annotation, and
+// a repeated name is numbered.
+func ReportColumnNames(report *helper.Report) []string {
+	names := make([]string, len(report.Columns))
+	seen := make(map[string]int)
+	for i, column := range report.Columns {
+		name := column.Name()
+		if name == "" {
+			name = "Annotation"
+		}
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s %d", name, seen[name])
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// WriteReportCsv writes the report as CSV with a header row. The first column
+// is the date and numeric values are written as the report holds them.
+func WriteReportCsv(report *helper.Report, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append([]string{"Date"}, ReportColumnNames(report)...)); err != nil {
+		return err
+	}
+
+	var err error
+	record := make([]string, len(report.Columns)+1)
+	ReadReport(report, func(date time.Time, values []string) {
+		if err != nil {
+			return
+		}
+		record[0] = date.Format(reportDateFormat(report))
+		for i, column := range report.Columns {
+			record[i+1] = reportValue(column, values[i])
+		}
+		err = writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// reportJson is the layout of a report written as JSON. Each row maps the
+// column names, and Date, to the values; numeric values that are missing
+// or not a number are null.
+type reportJson struct {
+	Title   string           `json:"title"`
+	Columns []string         `json:"columns"`
+	Rows    []map[string]any `json:"rows"`
+}
+
+// WriteReportJson writes the report as a JSON object holding its title, the
+// names of its columns and its rows.
+func WriteReportJson(report *helper.Report, w io.Writer) error {
+	names := ReportColumnNames(report)
+	doc := reportJson{Title: report.Title, Columns: names, Rows: []map[string]any{}}
+
+	ReadReport(report, func(date time.Time, values []string) {
+		row := make(map[string]any, len(values)+1)
+		row["Date"] = date.Format(reportDateFormat(report))
+		for i, column := range report.Columns {
+			value := reportValue(column, values[i])
+			if column.Type() != "number" {
+				row[names[i]] = value
+				continue
+			}
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+				row[names[i]] = nil
+			} else {
+				row[names[i]] = number
+			}
+		}
+		doc.Rows = append(doc.Rows, row)
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// reportValue returns a column's value as plain text. Annotations are held by
+// the report as JavaScript literals for its charts, quoted or null.
+func reportValue(column helper.ReportColumn, value string) string {
+	if column.Role() != "annotation" {
+		return value
+	}
+	if value == "null" {
+		return ""
+	}
+	return strings.Trim(value, `"`)
+}
+
+// reportDateFormat is the format of the report's dates.
+func reportDateFormat(report *helper.Report) string {
+	if report.DateFormat == "" {
+		return "2006-01-02"
+	}
+	return report.DateFormat
+}
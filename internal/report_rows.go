@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/cinar/indicator/v2/helper"
+)
+
+// ReadReport consumes the report one row at a time, reading every column in
+// lockstep as the report's own writer does, and calls fn with the date and
+// the values of the report's columns for each row.
+// The report cannot be written once it has been read.
+func ReadReport(report *helper.Report, fn func(date time.Time, values []string)) {
+	values := make([]string, len(report.Columns))
+	for date := range report.Date {
+		for i, column := range report.Columns {
+			values[i] = column.Value()
+		}
+		fn(date, values)
+	}
+}
+
+// ReportRows are the rows of a report held in memory, so that the report can
+// be written more than once without computing it again.
+type ReportRows struct {
+	report helper.Report
+	dates  []time.Time
+	values [][]string // By column
+}
+
+// NewReportRows reads the report's rows into memory.
+// The report cannot be written once it has been read.
+func NewReportRows(report *helper.Report) *ReportRows {
+	rows := &ReportRows{
+		report: *report,
+		values: make([][]string, len(report.Columns)),
+	}
+	ReadReport(report, func(date time.Time, values []string) {
+		rows.dates = append(rows.dates, date)
+		for i, value := range values {
+			rows.values[i] = append(rows.values[i], value)
+		}
+	})
+	return rows
+}
+
+// Report returns a copy of the report that reads its rows from memory.
+func (r *ReportRows) Report() *helper.Report {
+	report := r.report
+	report.Date = helper.SliceToChan(r.dates)
+	report.Columns = make([]helper.ReportColumn, len(r.report.Columns))
+	for i, column := range r.report.Columns {
+		report.Columns[i] = &replayedColumn{ReportColumn: column, values: r.values[i]}
+	}
+	return &report
+}
+
+// replayedColumn is a report column whose values are read from memory.
+type replayedColumn struct {
+	helper.ReportColumn
+	values []string
+}
+
+// Value returns the next value of the column.
+func (c *replayedColumn) Value() string {
+	value := c.values[0]
+	c.values = c.values[1:]
+	return value
+}
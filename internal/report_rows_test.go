@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/cinar/indicator/v2/helper"
+)
+
+// newTestReport returns a report of three days with a numeric and an annotation column.
+func newTestReport() *helper.Report {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dates := []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2)}
+
+	report := helper.NewReport("Test", helper.SliceToChan(dates))
+	report.AddColumn(helper.NewNumericReportColumn("Close", helper.SliceToChan([]float64{1, 2.5, 3})))
+	report.AddColumn(helper.NewAnnotationReportColumn(helper.SliceToChan([]string{"null", `"B"`, "null"})))
+	return report
+}
+
+func TestReportRows(t *testing.T) {
+	var expected bytes.Buffer
+	if err := WriteReportCsv(newTestReport(), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := NewReportRows(newTestReport())
+	for _, format := range []string{"csv", "json", "csv"} {
+		var actual bytes.Buffer
+		if err := WriteReport(rows.Report(), format, &actual); err != nil {
+			t.Fatal(err)
+		}
+		if format == "csv" && actual.String() != expected.String() {
+			t.Fatalf("actual %q expected %q", actual.String(), expected.String())
+		}
+	}
+
+	if title := rows.Report().Title; title != "Test" {
+		t.Fatalf("actual title %q expected Test", title)
+	}
+}
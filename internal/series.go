@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"context"
+	"sync"
+)
+
+// Series is a replayable sequence of values. It drains its source channel
+// into memory in the background, and any number of independent channels can
+// replay its values, each at its own pace. Unlike helper.Duplicate, a channel
+// that is never drained does not block the source or the other channels.
+type Series[T any] struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	values []T
+	done   bool
+}
+
+// NewSeries returns a complete series holding the given values.
+func NewSeries[T any](values []T) *Series[T] {
+	s := &Series[T]{values: values, done: true}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// NewSeriesFromChan returns a series that is filled from the channel.
+func NewSeriesFromChan[T any](c <-chan T) *Series[T] {
+	s := &Series[T]{}
+	s.cond = sync.NewCond(&s.mu)
+
+	go func() {
+		for v := range c {
+			s.mu.Lock()
+			s.values = append(s.values, v)
+			s.mu.Unlock()
+			s.cond.Broadcast()
+		}
+		s.mu.Lock()
+		s.done = true
+		s.mu.Unlock()
+		s.cond.Broadcast()
+	}()
+
+	return s
+}
+
+// Chan returns a new channel replaying all values of the series from the start.
+// The channel must be drained to the end: a goroutine feeds it until then,
+// and keeps the series in memory. Use ChanContext for a consumer that may stop
+// reading early.
+func (s *Series[T]) Chan() <-chan T {
+	return s.ChanContext(context.Background())
+}
+
+// ChanContext returns a new channel replaying all values of the series from
+// the start, which is closed early once ctx is done.
+func (s *Series[T]) ChanContext(ctx context.Context) <-chan T {
+	c := make(chan T)
+
+	go func() {
+		defer close(c)
+
+		// Wake the wait for more values when ctx is done.
+		stop := context.AfterFunc(ctx, func() {
+			s.mu.Lock()
+			s.mu.Unlock()
+			s.cond.Broadcast()
+		})
+		defer stop()
+
+		for i := 0; ; i++ {
+			s.mu.Lock()
+			for i >= len(s.values) && !s.done && ctx.Err() == nil {
+				s.cond.Wait()
+			}
+			if i >= len(s.values) || ctx.Err() != nil {
+				s.mu.Unlock()
+				return
+			}
+			v := s.values[i]
+			s.mu.Unlock()
+
+			select {
+			case c <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return c
+}
+
+// Values waits for the series to be complete and returns its values.
+func (s *Series[T]) Values() []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for !s.done {
+		s.cond.Wait()
+	}
+	return s.values
+}
+
+// Len waits for the series to be complete and returns its number of values.
+func (s *Series[T]) Len() int {
+	return len(s.Values())
+}
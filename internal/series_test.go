@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cinar/indicator/v2/helper"
+)
+
+func TestSeriesReplay(t *testing.T) {
+	input := []int{1, 2, 3, 4, 5}
+	s := NewSeriesFromChan(helper.SliceToChan(input))
+
+	for i := 0; i < 3; i++ {
+		actual := helper.ChanToSlice(s.Chan())
+		if !slices.Equal(actual, input) {
+			t.Fatalf("replay %d: actual %v expected %v", i, actual, input)
+		}
+	}
+
+	if s.Len() != len(input) {
+		t.Fatalf("actual length %d expected %d", s.Len(), len(input))
+	}
+	if !slices.Equal(s.Values(), input) {
+		t.Fatalf("actual values %v expected %v", s.Values(), input)
+	}
+}
+
+func TestSeriesConcurrentReaders(t *testing.T) {
+	input := make([]int, 1000)
+	for i := range input {
+		input[i] = i
+	}
+
+	// The source is only filled as the series drains it, so the readers
+	// start before the values are all in memory.
+	source := make(chan int)
+	s := NewSeriesFromChan(source)
+
+	var wg sync.WaitGroup
+	results := make([][]int, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = helper.ChanToSlice(s.Chan())
+		}(i)
+	}
+
+	for _, v := range input {
+		source <- v
+	}
+	close(source)
+	wg.Wait()
+
+	for i, actual := range results {
+		if !slices.Equal(actual, input) {
+			t.Fatalf("reader %d: actual %d values expected %d", i, len(actual), len(input))
+		}
+	}
+}
+
+func TestSeriesPartlyReadConsumer(t *testing.T) {
+	input := []int{1, 2, 3, 4, 5}
+	s := NewSeries(input)
+
+	// A channel read only in part, or not at all, does not hold up the others,
+	// as a branch of helper.Duplicate would.
+	partial := s.Chan()
+	<-partial
+	_ = s.Chan()
+
+	done := make(chan []int)
+	go func() {
+		done <- helper.ChanToSlice(s.Chan())
+	}()
+
+	select {
+	case actual := <-done:
+		if !slices.Equal(actual, input) {
+			t.Fatalf("actual %v expected %v", actual, input)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reader blocked by a partly read channel")
+	}
+}
+
+func TestSeriesChanContext(t *testing.T) {
+	source := make(chan int)
+	s := NewSeriesFromChan(source)
+	source <- 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := s.ChanContext(ctx)
+	if v := <-c; v != 1 {
+		t.Fatalf("actual %d expected 1", v)
+	}
+
+	// The channel is closed once ctx is done, both while waiting for the
+	// source and with values left unread.
+	cancel()
+	select {
+	case _, ok := <-c:
+		if ok {
+			t.Fatal("value received after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+
+	source <- 2
+	close(source)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	c = s.ChanContext(ctx)
+	<-c
+	cancel()
+	for range c {
+	}
+
+	if actual := helper.ChanToSlice(s.Chan()); !slices.Equal(actual, []int{1, 2}) {
+		t.Fatalf("actual %v expected [1 2]", actual)
+	}
+}
+
+// benchmarkReaders is the number of readers of each value, as in the combined strategies' reports.
+const benchmarkReaders = 8
+
+func benchmarkInput() []float64 {
+	input := make([]float64, 5000)
+	for i := range input {
+		input[i] = float64(i)
+	}
+	return input
+}
+
+// drainAll reads every channel to the end concurrently.
+func drainAll(channels []<-chan float64) {
+	var wg sync.WaitGroup
+	for _, c := range channels {
+		wg.Add(1)
+		go func(c <-chan float64) {
+			defer wg.Done()
+			for range c {
+			}
+		}(c)
+	}
+	wg.Wait()
+}
+
+func BenchmarkSeries(b *testing.B) {
+	input := benchmarkInput()
+
+	b.Run("Series", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s := NewSeriesFromChan(helper.SliceToChan(input))
+			channels := make([]<-chan float64, benchmarkReaders)
+			for j := range channels {
+				channels[j] = s.Chan()
+			}
+			drainAll(channels)
+		}
+	})
+
+	b.Run("Duplicate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			drainAll(helper.Duplicate(helper.SliceToChan(input), benchmarkReaders))
+		}
+	})
+}
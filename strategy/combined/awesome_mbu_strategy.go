@@ -34,6 +34,9 @@ package combined
 
 import (
 	"fmt"
+	"math"
+	"slices"
+	"strings"
 
 	"github.com/cinar/indicator/v2/asset"
 	"github.com/cinar/indicator/v2/helper"
@@ -41,6 +44,9 @@ import (
 	"github.com/cinar/indicator/v2/strategy/momentum"
 	"github.com/cinar/indicator/v2/strategy/trend"
 	"github.com/cinar/indicator/v2/volatility"
+	"github.com/vextasy/strategise/internal"
+	alt_volatility "github.com/vextasy/strategise/strategy/volatility"
+	"github.com/vextasy/strategise/strategy/warmup"
 )
 
 const (
@@ -63,11 +69,20 @@ type AwesomeMbuStrategy struct {
 	// RsiStrategy is the RSI strategy instance.
 	RsiStrategy *momentum.RsiStrategy
 
-	// OrStrategy is the OR strategy instance.
+	// Components are the strategies whose actions are combined.
+	Components []Component
+
+	// Combination defines how the actions of the components are combined.
+	Combination Combination
+
+	// OrStrategy is an OR strategy over the component strategies, kept for
+	// existing callers. Compute combines the components as Combination
+	// defines, which may not be an OR.
 	OrStrategy *strategy.OrStrategy
 
-	// UlcerIndexStrategy is the Ulcer Index strategy instance.
-	//UlcerIndexStrategy *momentum.UlcerIndexStrategy
+	// UlcerIndexStrategy is the optional Ulcer Index risk gate.
+	// The actions are not gated when it is nil.
+	UlcerIndexStrategy *alt_volatility.UlcerIndexStrategy
 }
 
 func NewAwesomeMbuStrategy() *AwesomeMbuStrategy {
@@ -77,88 +92,191 @@ func NewAwesomeMbuStrategy() *AwesomeMbuStrategy {
 	)
 }
 
+// NewAwesomeMbuStrategyWith returns the default strategy with the given RSI levels.
+// The RSI is not a component of the default strategy, so that its signals are
+// unchanged from before components could be configured; the levels only take
+// effect with a combination that uses the RSI.
 func NewAwesomeMbuStrategyWith(buyAt, sellAt float64) *AwesomeMbuStrategy {
+	// MACD and Awesome Oscillator outcomes are ORed together.
+	return NewAwesomeMbuStrategyWithCombination(buyAt, sellAt, OrCombination,
+		MacdComponent,
+		AwesomeOscillatorComponent,
+	)
+}
+
+// NewAwesomeMbuStrategyWithCombination returns a strategy combining the given components in the given way.
+func NewAwesomeMbuStrategyWithCombination(buyAt, sellAt float64, combination Combination, components ...Component) *AwesomeMbuStrategy {
 	s := &AwesomeMbuStrategy{
 		MacdStrategy:              trend.NewMacdStrategy(),
 		AwesomeOscillatorStrategy: momentum.NewAwesomeOscillatorStrategy(),
 		RsiStrategy:               momentum.NewRsiStrategyWith(buyAt, sellAt),
+		Components:                components,
+		Combination:               combination,
 		OrStrategy:                strategy.NewOrStrategy("OR Strategy"),
 	}
-	// MACD, Awesome Oscillator, and RSI outcomes are ORed together.
-	s.OrStrategy.Strategies = append(s.OrStrategy.Strategies,
-		s.MacdStrategy,
-		s.AwesomeOscillatorStrategy)
+	s.OrStrategy.Strategies = append(s.OrStrategy.Strategies, s.combiner().strategies...)
+	return s
+}
+
+// NewAwesomeMbuStrategyWithUlcerIndex returns a strategy whose actions are gated by
+// an Ulcer Index strategy with the given threshold and spike levels.
+func NewAwesomeMbuStrategyWithUlcerIndex(buyAt, sellAt, threshold, spike float64) *AwesomeMbuStrategy {
+	s := NewAwesomeMbuStrategyWith(buyAt, sellAt)
+	s.UlcerIndexStrategy = alt_volatility.NewUlcerIndexStrategyWith(threshold, spike)
 	return s
 }
 
 // Name returns the name of the strategy.
+// The RSI levels are only named when the RSI is used, and the combination
+// only when it differs from the default OR of MACD and AO.
 func (m *AwesomeMbuStrategy) Name() string {
-	return fmt.Sprintf("Awesome MBU Strategy (%.0f, %.0f)",
-		m.RsiStrategy.BuyAt,
-		m.RsiStrategy.SellAt,
-	)
+	components := make([]string, len(m.Components))
+	for i, component := range m.Components {
+		components[i] = string(component)
+	}
+	combination := fmt.Sprintf("%s %s", m.Combination, strings.Join(components, "+"))
+
+	var options []string
+	if slices.Contains(m.Components, RsiComponent) || m.Combination == RsiFilterCombination {
+		options = append(options, fmt.Sprintf("%.0f, %.0f",
+			m.RsiStrategy.BuyAt,
+			m.RsiStrategy.SellAt,
+		))
+	}
+	if combination != "OR MACD+AO" {
+		options = append(options, combination)
+	}
+	if m.UlcerIndexStrategy != nil {
+		options = append(options, fmt.Sprintf("UI %.0f, %.0f",
+			m.UlcerIndexStrategy.Threshold,
+			m.UlcerIndexStrategy.Spike,
+		))
+	}
+
+	if len(options) == 0 {
+		return "Awesome MBU Strategy"
+	}
+	return fmt.Sprintf("Awesome MBU Strategy (%s)", strings.Join(options, ", "))
+}
+
+// IdlePeriod is the number of snapshots consumed before the first meaningful action.
+func (m *AwesomeMbuStrategy) IdlePeriod() int {
+	period := warmup.Max(m.combiner().strategies...)
+	if m.Combination == RsiFilterCombination {
+		period = max(period, m.RsiStrategy.Rsi.IdlePeriod())
+	}
+	if m.UlcerIndexStrategy != nil {
+		period = max(period, m.UlcerIndexStrategy.IdlePeriod())
+	}
+	return period
+}
+
+// combiner returns the combiner for the configured components and combination.
+func (m *AwesomeMbuStrategy) combiner() *combiner {
+	cb := &combiner{
+		combination: m.Combination,
+		rsiBuyAt:    m.RsiStrategy.BuyAt,
+		rsiSellAt:   m.RsiStrategy.SellAt,
+		rsi: func(closings <-chan float64) <-chan float64 {
+			rsi := m.RsiStrategy.Rsi.Compute(closings)
+			return helper.Shift(rsi, m.RsiStrategy.Rsi.IdlePeriod(), math.NaN())
+		},
+	}
+
+	for _, component := range m.Components {
+		var st strategy.Strategy
+		switch component {
+		case MacdComponent:
+			st = m.MacdStrategy
+		case AwesomeOscillatorComponent:
+			st = m.AwesomeOscillatorStrategy
+		case RsiComponent:
+			// The RSI filter is applied to the other components rather than combined with them.
+			if m.Combination == RsiFilterCombination {
+				continue
+			}
+			st = m.RsiStrategy
+		case UlcerComponent:
+			st = m.ulcerIndexStrategy()
+		default:
+			continue
+		}
+		cb.components = append(cb.components, component)
+		cb.strategies = append(cb.strategies, st)
+	}
+
+	return cb
+}
+
+// ulcerIndexStrategy returns the Ulcer Index strategy used as a component,
+// which is the gate's when the actions are gated.
+func (m *AwesomeMbuStrategy) ulcerIndexStrategy() *alt_volatility.UlcerIndexStrategy {
+	if m.UlcerIndexStrategy != nil {
+		return m.UlcerIndexStrategy
+	}
+	return alt_volatility.NewUlcerIndexStrategy()
 }
 
 // Compute processes the provided asset snapshots and generates a stream of actionable recommendations.
-func (m *AwesomeMbuStrategy) Compute(snapshots <-chan *asset.Snapshot) <-chan strategy.Action {
-	actions := m.OrStrategy.Compute(snapshots)
-	actions = strategy.NormalizeActions(actions)
+func (m *AwesomeMbuStrategy) Compute(c <-chan *asset.Snapshot) <-chan strategy.Action {
+	if m.UlcerIndexStrategy == nil {
+		return m.ungated(c)
+	}
+
+	snapshots := internal.NewSeriesFromChan(c)
+	return m.UlcerIndexStrategy.Gate(m.ungated(snapshots.Chan()), snapshots.Chan())
+}
 
-	return actions
+// ungated returns the normalized actions before any Ulcer Index gate is applied.
+func (m *AwesomeMbuStrategy) ungated(c <-chan *asset.Snapshot) <-chan strategy.Action {
+	actions := helper.Map(m.combiner().events(c), func(e combinedEvent) strategy.Action {
+		return e.action
+	})
+	return strategy.NormalizeActions(actions)
 }
 
 func (m *AwesomeMbuStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
-	//
-	// snapshots[0] -> dates
-	// snapshots[1] -> closings[0] -> macds, signals
-	//                 closings[1] -> rsi
-	//                 closings[2] -> ao
-	//                 closings[3] -> closings chart
-	//                 closings[4] -> ulcer index
-	// snapshots[2] -> highs       -> ao highs
-	// snapshots[3] -> lows        -> ao lows
-	// snapshots[4] -> actions     -> annotations
-	//              -> outcomes
-	// snapshots[5] -> macd_actions-> macd_annotations
-	// snapshots[6] -> rsi_actions -> rsi_annotations
-	// snapshots[7] -> ao_actions  -> ao_annotations
-	//
-	snapshots := helper.Duplicate(c, 8)
-
-	dates := asset.SnapshotsAsDates(snapshots[0])
-	closings := helper.Duplicate(asset.SnapshotsAsClosings(snapshots[1]), 5)
+	snapshots := internal.NewSeriesFromChan(c)
+	closings := internal.NewSeriesFromChan(asset.SnapshotsAsClosings(snapshots.Chan()))
+
+	dates := asset.SnapshotsAsDates(snapshots.Chan())
 
 	// MACD
-	macds, signals := m.MacdStrategy.Macd.Compute(closings[0])
+	macds, signals := m.MacdStrategy.Macd.Compute(closings.Chan())
 	macds = helper.Shift(macds, m.MacdStrategy.Macd.IdlePeriod(), 0)
 	signals = helper.Shift(signals, m.MacdStrategy.Macd.IdlePeriod(), 0)
 
 	// RSI
-	rsi := m.RsiStrategy.Rsi.Compute(closings[1])
+	rsi := m.RsiStrategy.Rsi.Compute(closings.Chan())
 	rsi = helper.Shift(rsi, m.RsiStrategy.Rsi.IdlePeriod(), 0)
 
 	// Awesome Oscillator
-	highs := asset.SnapshotsAsHighs(snapshots[2])
-	lows := asset.SnapshotsAsLows(snapshots[3])
+	highs := asset.SnapshotsAsHighs(snapshots.Chan())
+	lows := asset.SnapshotsAsLows(snapshots.Chan())
 	ao := m.AwesomeOscillatorStrategy.AwesomeOscillator.Compute(highs, lows)
 	ao = helper.Shift(ao, m.AwesomeOscillatorStrategy.AwesomeOscillator.IdlePeriod(), 0)
 
-	// MARU outcomes & annotations
-	actions, outcomes := strategy.ComputeWithOutcome(m, snapshots[4])
-	annotations := strategy.ActionsToAnnotations(actions)
+	// MARU outcomes & annotations naming the components that produced each action
+	actions, outcomes := strategy.ComputeWithOutcome(m, snapshots.Chan())
+	annotations := helper.Operate(actions, m.combiner().events(snapshots.Chan()), func(action strategy.Action, e combinedEvent) string {
+		return e.annotation(action)
+	})
 	outcomes = helper.MultiplyBy(outcomes, 100)
 
 	// Ulcer index
 	ulcer_index_indicator := volatility.NewUlcerIndex[float64]()
-	ulcer_index := ulcer_index_indicator.Compute(closings[4])
+	if m.UlcerIndexStrategy != nil {
+		ulcer_index_indicator = m.UlcerIndexStrategy.UlcerIndex
+	}
+	ulcer_index := ulcer_index_indicator.Compute(closings.Chan())
 	ulcer_index = helper.Shift(ulcer_index, ulcer_index_indicator.IdlePeriod(), 0)
 
 	// Other annotations
-	macd_actions := m.MacdStrategy.Compute(snapshots[5])
+	macd_actions := m.MacdStrategy.Compute(snapshots.Chan())
 	macd_annotations := strategy.ActionsToAnnotations(macd_actions)
-	rsi_actions := m.RsiStrategy.Compute(snapshots[6])
+	rsi_actions := m.RsiStrategy.Compute(snapshots.Chan())
 	rsi_annotations := strategy.ActionsToAnnotations(rsi_actions)
-	ao_actions := m.AwesomeOscillatorStrategy.Compute(snapshots[7])
+	ao_actions := m.AwesomeOscillatorStrategy.Compute(snapshots.Chan())
 	ao_annotations := strategy.ActionsToAnnotations(ao_actions)
 
 	report := helper.NewReport(m.Name(), dates) // Close
@@ -168,7 +286,7 @@ func (m *AwesomeMbuStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
 	report.AddChart()                           // Outcome
 	report.AddChart()                           // Ulcer Index
 
-	report.AddColumn(helper.NewNumericReportColumn("Close", closings[3]))
+	report.AddColumn(helper.NewNumericReportColumn("Close", closings.Chan()))
 
 	report.AddColumn(helper.NewNumericReportColumn("MACD", macds), 1)
 	report.AddColumn(helper.NewNumericReportColumn("Signal", signals), 1)
@@ -180,7 +298,20 @@ func (m *AwesomeMbuStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
 	report.AddColumn(helper.NewNumericReportColumn("AO", ao), 3)
 	report.AddColumn(helper.NewAnnotationReportColumn(ao_annotations), 3)
 
-	report.AddColumn(helper.NewNumericReportColumn("Ulcer", ulcer_index), 4)
+	if m.UlcerIndexStrategy == nil {
+		report.AddColumn(helper.NewNumericReportColumn("Ulcer", ulcer_index), 4)
+	} else {
+		ulcer_indexes := internal.NewSeriesFromChan(ulcer_index)
+		ulcer_annotations := m.UlcerIndexStrategy.GateAnnotations(m.ungated(snapshots.Chan()), snapshots.Chan())
+		report.AddColumn(helper.NewNumericReportColumn("Ulcer", ulcer_indexes.Chan()), 4)
+		report.AddColumn(helper.NewAnnotationReportColumn(ulcer_annotations), 4)
+		report.AddColumn(helper.NewNumericReportColumn("Threshold", helper.Map(ulcer_indexes.Chan(), func(float64) float64 {
+			return m.UlcerIndexStrategy.Threshold
+		})), 4)
+		report.AddColumn(helper.NewNumericReportColumn("Spike", helper.Map(ulcer_indexes.Chan(), func(float64) float64 {
+			return m.UlcerIndexStrategy.Spike
+		})), 4)
+	}
 
 	report.AddColumn(helper.NewAnnotationReportColumn(annotations), 0)
 
@@ -0,0 +1,64 @@
+package combined
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAwesomeMbuStrategyName(t *testing.T) {
+	tests := []struct {
+		s    *AwesomeMbuStrategy
+		name string
+	}{
+		{NewAwesomeMbuStrategyWith(40, 60), "Awesome MBU Strategy"},
+		{NewAwesomeMbuStrategyWithUlcerIndex(40, 60, 5, 10), "Awesome MBU Strategy (UI 5, 10)"},
+		{
+			NewAwesomeMbuStrategyWithCombination(40, 60, OrCombination, MacdComponent, AwesomeOscillatorComponent, RsiComponent),
+			"Awesome MBU Strategy (40, 60, OR MACD+AO+RSI)",
+		},
+		{
+			NewAwesomeMbuStrategyWithCombination(30, 70, RsiFilterCombination, MacdComponent, AwesomeOscillatorComponent),
+			"Awesome MBU Strategy (30, 70, RSI FILTER MACD+AO)",
+		},
+		{
+			NewAwesomeMbuStrategyWithCombination(40, 60, AndCombination, MacdComponent, UlcerComponent),
+			"Awesome MBU Strategy (AND MACD+UI)",
+		},
+	}
+	for _, test := range tests {
+		if actual := test.s.Name(); actual != test.name {
+			t.Fatalf("actual %q expected %q", actual, test.name)
+		}
+	}
+}
+
+func TestAwesomeMbuStrategyComponents(t *testing.T) {
+	tests := []struct {
+		combination Combination
+		components  []Component
+		combined    []Component
+	}{
+		{OrCombination, []Component{MacdComponent, AwesomeOscillatorComponent}, []Component{MacdComponent, AwesomeOscillatorComponent}},
+		{MajorityCombination, []Component{MacdComponent, RsiComponent, UlcerComponent}, []Component{MacdComponent, RsiComponent, UlcerComponent}},
+		// The RSI filters the other components rather than being combined with them.
+		{RsiFilterCombination, []Component{MacdComponent, RsiComponent}, []Component{MacdComponent}},
+		{OrCombination, []Component{"Unknown", AwesomeOscillatorComponent}, []Component{AwesomeOscillatorComponent}},
+	}
+	for _, test := range tests {
+		s := NewAwesomeMbuStrategyWithCombination(40, 60, test.combination, test.components...)
+		cb := s.combiner()
+		if !slices.Equal(cb.components, test.combined) || len(cb.strategies) != len(test.combined) {
+			t.Fatalf("%s %v: actual components %v expected %v", test.combination, test.components, cb.components, test.combined)
+		}
+		if len(s.OrStrategy.Strategies) != len(test.combined) {
+			t.Fatalf("%s %v: actual %d OR strategies expected %d", test.combination, test.components, len(s.OrStrategy.Strategies), len(test.combined))
+		}
+	}
+
+	// The Ulcer Index component is the gate's when there is one.
+	s := NewAwesomeMbuStrategyWithUlcerIndex(40, 60, 3, 8)
+	s.Components = []Component{UlcerComponent}
+	if st := s.combiner().strategies[0]; st != s.UlcerIndexStrategy {
+		t.Fatalf("actual %v expected the gate's Ulcer Index strategy", st)
+	}
+}
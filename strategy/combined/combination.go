@@ -0,0 +1,204 @@
+package combined
+
+import (
+	"math"
+	"strings"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/vextasy/strategise/internal"
+)
+
+// Component identifies one of the strategies that a combined strategy is built from.
+type Component string
+
+const (
+	// MacdComponent is the MACD strategy.
+	MacdComponent Component = "MACD"
+
+	// AwesomeOscillatorComponent is the Awesome Oscillator strategy.
+	AwesomeOscillatorComponent Component = "AO"
+
+	// RsiComponent is the RSI strategy.
+	RsiComponent Component = "RSI"
+
+	// UlcerComponent is the Ulcer Index strategy. It only ever sells, when the
+	// Ulcer Index spikes, so it never agrees to a Buy under AND.
+	UlcerComponent Component = "UI"
+)
+
+// Combination defines how the actions of the components are combined.
+type Combination string
+
+const (
+	// OrCombination acts whenever any component acts.
+	OrCombination Combination = "OR"
+
+	// AndCombination acts once all components agree.
+	AndCombination Combination = "AND"
+
+	// MajorityCombination acts once more than half of the components agree.
+	MajorityCombination Combination = "MAJORITY"
+
+	// RsiFilterCombination ORs the components other than RSI and only accepts
+	// a Buy while the RSI is below its sell level and a Sell while it is above its buy level.
+	RsiFilterCombination Combination = "RSI FILTER"
+)
+
+// combinedEvent is a combined action together with the components that produced it.
+type combinedEvent struct {
+	action strategy.Action
+	source string
+}
+
+// annotation returns the action annotation followed by the components that produced it.
+func (e combinedEvent) annotation(action strategy.Action) string {
+	if action == strategy.Hold {
+		return ""
+	}
+	if e.action != action || e.source == "" {
+		return action.Annotation()
+	}
+	return action.Annotation() + " " + e.source
+}
+
+// combiner combines the actions of a set of component strategies.
+type combiner struct {
+	combination Combination
+	components  []Component
+	strategies  []strategy.Strategy
+
+	// rsi computes the RSI values used by the RSI filter, and the buy and sell levels.
+	rsi                 func(<-chan float64) <-chan float64
+	rsiBuyAt, rsiSellAt float64
+}
+
+// events processes the provided asset snapshots and generates a stream of
+// combined actions annotated with the components that produced them.
+func (cb *combiner) events(c <-chan *asset.Snapshot) <-chan combinedEvent {
+	if len(cb.strategies) == 0 {
+		return helper.Map(c, func(*asset.Snapshot) combinedEvent {
+			return combinedEvent{action: strategy.Hold}
+		})
+	}
+
+	filter := cb.combination == RsiFilterCombination
+	snapshots := internal.NewSeriesFromChan(c)
+
+	inputs := make([]<-chan strategy.Action, len(cb.strategies))
+	for i, st := range cb.strategies {
+		inputs[i] = st.Compute(snapshots.Chan())
+	}
+
+	var rsi <-chan float64
+	if filter {
+		rsi = cb.rsi(asset.SnapshotsAsClosings(snapshots.Chan()))
+	}
+
+	events := make(chan combinedEvent)
+
+	go func() {
+		defer close(events)
+
+		actions := make([]strategy.Action, len(inputs))
+		states := make([]strategy.Action, len(inputs))
+		consensus := strategy.Hold
+
+		for {
+			for i, input := range inputs {
+				action, ok := <-input
+				if !ok {
+					for _, input := range inputs {
+						helper.Drain(input)
+					}
+					if rsi != nil {
+						helper.Drain(rsi)
+					}
+					return
+				}
+				actions[i] = action
+				if action != strategy.Hold {
+					states[i] = action
+				}
+			}
+
+			rsiValue := math.NaN()
+			if rsi != nil {
+				rsiValue = <-rsi
+			}
+
+			switch cb.combination {
+			case AndCombination, MajorityCombination:
+				next := cb.consensus(states)
+				if next == strategy.Hold || next == consensus {
+					events <- combinedEvent{action: strategy.Hold}
+					continue
+				}
+				consensus = next
+				events <- combinedEvent{action: next, source: cb.source(states, next, "+")}
+
+			default:
+				next := strategy.Hold
+				for _, action := range actions {
+					if action != strategy.Hold {
+						next = action
+						break
+					}
+				}
+				if filter && next != strategy.Hold && !cb.rsiConfirms(next, rsiValue) {
+					next = strategy.Hold
+				}
+				source := cb.source(actions, next, "|")
+				if filter && next != strategy.Hold {
+					source += " & RSI"
+				}
+				events <- combinedEvent{action: next, source: source}
+			}
+		}
+	}()
+
+	return events
+}
+
+// consensus returns the action that all or most of the component states agree on.
+func (cb *combiner) consensus(states []strategy.Action) strategy.Action {
+	for _, action := range []strategy.Action{strategy.Buy, strategy.Sell} {
+		agree := 0
+		for _, state := range states {
+			if state == action {
+				agree++
+			}
+		}
+		if cb.combination == AndCombination && agree == len(states) {
+			return action
+		}
+		if cb.combination == MajorityCombination && agree*2 > len(states) {
+			return action
+		}
+	}
+	return strategy.Hold
+}
+
+// source names the components whose action matches the given action.
+func (cb *combiner) source(actions []strategy.Action, action strategy.Action, sep string) string {
+	if action == strategy.Hold {
+		return ""
+	}
+	var names []string
+	for i, a := range actions {
+		if a == action {
+			names = append(names, string(cb.components[i]))
+		}
+	}
+	return strings.Join(names, sep)
+}
+
+// rsiConfirms reports whether the RSI value allows the action.
+// A NaN value, before the RSI has a full period, never confirms.
+func (cb *combiner) rsiConfirms(action strategy.Action, rsi float64) bool {
+	if action == strategy.Buy {
+		return rsi < cb.rsiSellAt
+	}
+	return rsi > cb.rsiBuyAt
+}
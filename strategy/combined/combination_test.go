@@ -0,0 +1,148 @@
+package combined
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+)
+
+const (
+	B = strategy.Buy
+	S = strategy.Sell
+	H = strategy.Hold
+)
+
+// fixedStrategy takes the same actions whatever the prices.
+type fixedStrategy []strategy.Action
+
+func (s fixedStrategy) Name() string { return "Fixed" }
+
+func (s fixedStrategy) Compute(c <-chan *asset.Snapshot) <-chan strategy.Action {
+	i := 0
+	return helper.Map(c, func(*asset.Snapshot) strategy.Action {
+		action := H
+		if i < len(s) {
+			action = s[i]
+		}
+		i++
+		return action
+	})
+}
+
+func (s fixedStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
+	return helper.NewReport(s.Name(), asset.SnapshotsAsDates(c))
+}
+
+// newTestSnapshots returns a snapshot for each closing price, one day apart.
+func newTestSnapshots(closings ...float64) []*asset.Snapshot {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := make([]*asset.Snapshot, len(closings))
+	for i, close := range closings {
+		snapshots[i] = &asset.Snapshot{Date: start.AddDate(0, 0, i), Open: close, High: close, Low: close, Close: close}
+	}
+	return snapshots
+}
+
+func TestCombinerEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		combination Combination
+		components  []Component
+		strategies  []strategy.Strategy
+		closings    []float64 // Also the RSI values
+		actions     []strategy.Action
+		sources     []string
+	}{
+		{
+			"OR",
+			OrCombination,
+			[]Component{MacdComponent, AwesomeOscillatorComponent},
+			[]strategy.Strategy{fixedStrategy{B, H, H, S, H}, fixedStrategy{H, B, H, H, S}},
+			[]float64{1, 2, 3, 4, 5},
+			[]strategy.Action{B, B, H, S, S},
+			[]string{"MACD", "AO", "", "MACD", "AO"},
+		},
+		{
+			"AND",
+			AndCombination,
+			[]Component{MacdComponent, AwesomeOscillatorComponent},
+			[]strategy.Strategy{fixedStrategy{B, H, H, S, H}, fixedStrategy{H, B, H, H, S}},
+			[]float64{1, 2, 3, 4, 5},
+			[]strategy.Action{H, B, H, H, S},
+			[]string{"", "MACD+AO", "", "", "MACD+AO"},
+		},
+		{
+			"MAJORITY",
+			MajorityCombination,
+			[]Component{MacdComponent, AwesomeOscillatorComponent, RsiComponent},
+			[]strategy.Strategy{fixedStrategy{B}, fixedStrategy{H, B}, fixedStrategy{S, H, H, B}},
+			[]float64{1, 2, 3, 4},
+			[]strategy.Action{H, B, H, H},
+			[]string{"", "MACD+AO", "", ""},
+		},
+		{
+			"RSI filter",
+			RsiFilterCombination,
+			[]Component{MacdComponent},
+			[]strategy.Strategy{fixedStrategy{B, B, S, S}},
+			[]float64{80, 50, 20, 50},
+			[]strategy.Action{H, B, H, S},
+			[]string{"", "MACD & RSI", "", "MACD & RSI"},
+		},
+		{
+			"no components",
+			OrCombination,
+			nil,
+			nil,
+			[]float64{1, 2},
+			[]strategy.Action{H, H},
+			[]string{"", ""},
+		},
+	}
+	for _, test := range tests {
+		cb := &combiner{
+			combination: test.combination,
+			components:  test.components,
+			strategies:  test.strategies,
+			rsi:         func(closings <-chan float64) <-chan float64 { return closings },
+			rsiBuyAt:    30,
+			rsiSellAt:   70,
+		}
+		events := helper.ChanToSlice(cb.events(helper.SliceToChan(newTestSnapshots(test.closings...))))
+
+		actions := make([]strategy.Action, len(events))
+		sources := make([]string, len(events))
+		for i, e := range events {
+			actions[i] = e.action
+			sources[i] = e.source
+		}
+		if !slices.Equal(actions, test.actions) {
+			t.Fatalf("%s: actual actions %v expected %v", test.name, actions, test.actions)
+		}
+		if !slices.Equal(sources, test.sources) {
+			t.Fatalf("%s: actual sources %q expected %q", test.name, sources, test.sources)
+		}
+	}
+}
+
+func TestCombinedEventAnnotation(t *testing.T) {
+	tests := []struct {
+		e        combinedEvent
+		action   strategy.Action
+		expected string
+	}{
+		{combinedEvent{action: B, source: "MACD"}, B, B.Annotation() + " MACD"},
+		{combinedEvent{action: B, source: "MACD"}, H, ""},
+		{combinedEvent{action: S, source: "AO"}, B, B.Annotation()},
+		{combinedEvent{action: S}, S, S.Annotation()},
+	}
+	for _, test := range tests {
+		if actual := test.e.annotation(test.action); actual != test.expected {
+			t.Fatalf("%+v: actual %q expected %q", test.e, actual, test.expected)
+		}
+	}
+}
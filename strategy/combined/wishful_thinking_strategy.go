@@ -31,7 +31,10 @@ import (
 	"github.com/cinar/indicator/v2/strategy"
 	"github.com/cinar/indicator/v2/strategy/momentum"
 	"github.com/cinar/indicator/v2/volatility"
+	"github.com/vextasy/strategise/internal"
 	alt_trend "github.com/vextasy/strategise/strategy/trend"
+	alt_volatility "github.com/vextasy/strategise/strategy/volatility"
+	"github.com/vextasy/strategise/strategy/warmup"
 )
 
 const (
@@ -58,8 +61,9 @@ type WishfulThinkingStrategy struct {
 	// OrStrategy is the OR strategy instance.
 	OrStrategy *strategy.OrStrategy
 
-	// UlcerIndexStrategy is the Ulcer Index strategy instance.
-	//UlcerIndexStrategy *momentum.UlcerIndexStrategy
+	// UlcerIndexStrategy is the optional Ulcer Index risk gate.
+	// The actions are not gated when it is nil.
+	UlcerIndexStrategy *alt_volatility.UlcerIndexStrategy
 }
 
 func NewWishfulThinkingStrategy() *WishfulThinkingStrategy {
@@ -85,74 +89,95 @@ func NewWishfulThinkingStrategyWith(buyAt, sellAt float64) *WishfulThinkingStrat
 	return s
 }
 
+// NewWishfulThinkingStrategyWithUlcerIndex returns a strategy whose actions are gated by
+// an Ulcer Index strategy with the given threshold and spike levels.
+func NewWishfulThinkingStrategyWithUlcerIndex(buyAt, sellAt, threshold, spike float64) *WishfulThinkingStrategy {
+	s := NewWishfulThinkingStrategyWith(buyAt, sellAt)
+	s.UlcerIndexStrategy = alt_volatility.NewUlcerIndexStrategyWith(threshold, spike)
+	return s
+}
+
 // Name returns the name of the strategy.
 func (m *WishfulThinkingStrategy) Name() string {
+	if m.UlcerIndexStrategy != nil {
+		return fmt.Sprintf("Wishful Thinking Strategy (%.0f, %.0f, UI %.0f, %.0f)",
+			m.RsiStrategy.BuyAt,
+			m.RsiStrategy.SellAt,
+			m.UlcerIndexStrategy.Threshold,
+			m.UlcerIndexStrategy.Spike,
+		)
+	}
 	return fmt.Sprintf("Wishful Thinking Strategy (%.0f, %.0f)",
 		m.RsiStrategy.BuyAt,
 		m.RsiStrategy.SellAt,
 	)
 }
 
+// IdlePeriod is the number of snapshots consumed before the first meaningful action.
+func (m *WishfulThinkingStrategy) IdlePeriod() int {
+	period := warmup.IdlePeriod(m.OrStrategy)
+	if m.UlcerIndexStrategy != nil {
+		period = max(period, m.UlcerIndexStrategy.IdlePeriod())
+	}
+	return period
+}
+
 // Compute processes the provided asset snapshots and generates a stream of actionable recommendations.
-func (m *WishfulThinkingStrategy) Compute(snapshots <-chan *asset.Snapshot) <-chan strategy.Action {
-	actions := m.OrStrategy.Compute(snapshots)
-	actions = strategy.NormalizeActions(actions)
+func (m *WishfulThinkingStrategy) Compute(c <-chan *asset.Snapshot) <-chan strategy.Action {
+	if m.UlcerIndexStrategy == nil {
+		return m.ungated(c)
+	}
+
+	snapshots := internal.NewSeriesFromChan(c)
+	return m.UlcerIndexStrategy.Gate(m.ungated(snapshots.Chan()), snapshots.Chan())
+}
 
-	return actions
+// ungated returns the normalized actions before any Ulcer Index gate is applied.
+func (m *WishfulThinkingStrategy) ungated(c <-chan *asset.Snapshot) <-chan strategy.Action {
+	actions := m.OrStrategy.Compute(c)
+	return strategy.NormalizeActions(actions)
 }
 
 func (m *WishfulThinkingStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
-	//
-	// snapshots[0] -> dates
-	// snapshots[1] -> closings[0] -> macds, signals
-	//                 closings[1] -> rsi
-	//                 closings[2] -> ao
-	//                 closings[3] -> closings chart
-	//                 closings[4] -> ulcer index
-	// snapshots[2] -> highs       -> ao highs
-	// snapshots[3] -> lows        -> ao lows
-	// snapshots[4] -> actions     -> annotations
-	//              -> outcomes
-	// snapshots[5] -> macd_actions-> macd_annotations
-	// snapshots[6] -> rsi_actions -> rsi_annotations
-	// snapshots[7] -> ao_actions  -> ao_annotations
-	//
-	snapshots := helper.Duplicate(c, 8)
-
-	dates := asset.SnapshotsAsDates(snapshots[0])
-	closings := helper.Duplicate(asset.SnapshotsAsClosings(snapshots[1]), 5)
+	snapshots := internal.NewSeriesFromChan(c)
+	closings := internal.NewSeriesFromChan(asset.SnapshotsAsClosings(snapshots.Chan()))
+
+	dates := asset.SnapshotsAsDates(snapshots.Chan())
 
 	// MACD
-	macds, signals := m.MacdStrategy.Macd.Compute(closings[0])
+	macds, signals := m.MacdStrategy.Macd.Compute(closings.Chan())
 	macds = helper.Shift(macds, m.MacdStrategy.Macd.IdlePeriod(), 0)
 	signals = helper.Shift(signals, m.MacdStrategy.Macd.IdlePeriod(), 0)
 
 	// RSI
-	rsi := m.RsiStrategy.Rsi.Compute(closings[1])
+	rsi := m.RsiStrategy.Rsi.Compute(closings.Chan())
 	rsi = helper.Shift(rsi, m.RsiStrategy.Rsi.IdlePeriod(), 0)
 
 	// Awesome Oscillator
-	highs := asset.SnapshotsAsHighs(snapshots[2])
-	lows := asset.SnapshotsAsLows(snapshots[3])
+	highs := asset.SnapshotsAsHighs(snapshots.Chan())
+	lows := asset.SnapshotsAsLows(snapshots.Chan())
 	ao := m.AwesomeOscillatorStrategy.AwesomeOscillator.Compute(highs, lows)
 	ao = helper.Shift(ao, m.AwesomeOscillatorStrategy.AwesomeOscillator.IdlePeriod(), 0)
 
 	// Wishful Thinking outcomes & annotations
-	actions, outcomes := strategy.ComputeWithOutcome(m, snapshots[4])
+	actions, outcomes := strategy.ComputeWithOutcome(m, snapshots.Chan())
 	annotations := strategy.ActionsToAnnotations(actions)
 	outcomes = helper.MultiplyBy(outcomes, 100)
 
 	// Ulcer index
 	ulcer_index_indicator := volatility.NewUlcerIndex[float64]()
-	ulcer_index := ulcer_index_indicator.Compute(closings[4])
+	if m.UlcerIndexStrategy != nil {
+		ulcer_index_indicator = m.UlcerIndexStrategy.UlcerIndex
+	}
+	ulcer_index := ulcer_index_indicator.Compute(closings.Chan())
 	ulcer_index = helper.Shift(ulcer_index, ulcer_index_indicator.IdlePeriod(), 0)
 
 	// Other annotations
-	macd_actions := m.MacdStrategy.Compute(snapshots[5])
+	macd_actions := m.MacdStrategy.Compute(snapshots.Chan())
 	macd_annotations := strategy.ActionsToAnnotations(macd_actions)
-	rsi_actions := m.RsiStrategy.Compute(snapshots[6])
+	rsi_actions := m.RsiStrategy.Compute(snapshots.Chan())
 	rsi_annotations := strategy.ActionsToAnnotations(rsi_actions)
-	ao_actions := m.AwesomeOscillatorStrategy.Compute(snapshots[7])
+	ao_actions := m.AwesomeOscillatorStrategy.Compute(snapshots.Chan())
 	ao_annotations := strategy.ActionsToAnnotations(ao_actions)
 
 	report := helper.NewReport(m.Name(), dates) // Close
@@ -162,7 +187,7 @@ func (m *WishfulThinkingStrategy) Report(c <-chan *asset.Snapshot) *helper.Repor
 	report.AddChart()                           // Outcome
 	report.AddChart()                           // Ulcer Index
 
-	report.AddColumn(helper.NewNumericReportColumn("Close", closings[3]))
+	report.AddColumn(helper.NewNumericReportColumn("Close", closings.Chan()))
 
 	report.AddColumn(helper.NewNumericReportColumn("MACD", macds), 1)
 	report.AddColumn(helper.NewNumericReportColumn("Signal", signals), 1)
@@ -174,7 +199,20 @@ func (m *WishfulThinkingStrategy) Report(c <-chan *asset.Snapshot) *helper.Repor
 	report.AddColumn(helper.NewNumericReportColumn("AO", ao), 3)
 	report.AddColumn(helper.NewAnnotationReportColumn(ao_annotations), 3)
 
-	report.AddColumn(helper.NewNumericReportColumn("Ulcer", ulcer_index), 4)
+	if m.UlcerIndexStrategy == nil {
+		report.AddColumn(helper.NewNumericReportColumn("Ulcer", ulcer_index), 4)
+	} else {
+		ulcer_indexes := internal.NewSeriesFromChan(ulcer_index)
+		ulcer_annotations := m.UlcerIndexStrategy.GateAnnotations(m.ungated(snapshots.Chan()), snapshots.Chan())
+		report.AddColumn(helper.NewNumericReportColumn("Ulcer", ulcer_indexes.Chan()), 4)
+		report.AddColumn(helper.NewAnnotationReportColumn(ulcer_annotations), 4)
+		report.AddColumn(helper.NewNumericReportColumn("Threshold", helper.Map(ulcer_indexes.Chan(), func(float64) float64 {
+			return m.UlcerIndexStrategy.Threshold
+		})), 4)
+		report.AddColumn(helper.NewNumericReportColumn("Spike", helper.Map(ulcer_indexes.Chan(), func(float64) float64 {
+			return m.UlcerIndexStrategy.Spike
+		})), 4)
+	}
 
 	report.AddColumn(helper.NewAnnotationReportColumn(annotations), 0)
 
@@ -0,0 +1,144 @@
+package decorator
+
+import (
+	"fmt"
+
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+)
+
+// ActionFilter transforms a stream of normalized actions into another stream
+// of normalized actions, typically to suppress whipsaw trades.
+type ActionFilter interface {
+	// Name returns a short description of the filter.
+	Name() string
+
+	// Filter processes the provided actions.
+	Filter(actions <-chan strategy.Action) <-chan strategy.Action
+}
+
+// MinimumHoldFilter delays a Sell until at least Bars bars have passed since the Buy.
+// The Sell is dropped if the actions turn back to Buy in the meantime.
+type MinimumHoldFilter struct {
+	Bars int
+}
+
+// PersistenceFilter only acts on a signal once it has persisted for Bars bars.
+type PersistenceFilter struct {
+	Bars int
+}
+
+// CooldownFilter defers any reversal within Bars bars of the previous action.
+// The reversal is acted on once the cooldown has passed, unless the actions
+// have turned back in the meantime.
+type CooldownFilter struct {
+	Bars int
+}
+
+// NewMinimumHoldFilter function initializes a new minimum holding period filter.
+func NewMinimumHoldFilter(bars int) *MinimumHoldFilter {
+	return &MinimumHoldFilter{Bars: bars}
+}
+
+// NewPersistenceFilter function initializes a new signal persistence filter.
+func NewPersistenceFilter(bars int) *PersistenceFilter {
+	return &PersistenceFilter{Bars: bars}
+}
+
+// NewCooldownFilter function initializes a new signal cooldown filter.
+func NewCooldownFilter(bars int) *CooldownFilter {
+	return &CooldownFilter{Bars: bars}
+}
+
+// Name returns the name of the filter.
+func (f *MinimumHoldFilter) Name() string {
+	return fmt.Sprintf("Min Hold %d", f.Bars)
+}
+
+// Filter processes the provided actions.
+func (f *MinimumHoldFilter) Filter(actions <-chan strategy.Action) <-chan strategy.Action {
+	invested := false
+	pendingSell := false
+	held := 0
+
+	return helper.Map(actions, func(action strategy.Action) strategy.Action {
+		if invested {
+			held++
+		}
+
+		switch {
+		case action == strategy.Buy && !invested:
+			invested = true
+			pendingSell = false
+			held = 0
+			return strategy.Buy
+
+		case action == strategy.Buy && invested:
+			pendingSell = false
+
+		case action == strategy.Sell && invested:
+			pendingSell = true
+		}
+
+		if pendingSell && held >= f.Bars {
+			invested = false
+			pendingSell = false
+			return strategy.Sell
+		}
+		return strategy.Hold
+	})
+}
+
+// Name returns the name of the filter.
+func (f *PersistenceFilter) Name() string {
+	return fmt.Sprintf("Persist %d", f.Bars)
+}
+
+// Filter processes the provided actions.
+func (f *PersistenceFilter) Filter(actions <-chan strategy.Action) <-chan strategy.Action {
+	desired := strategy.Hold
+	position := strategy.Sell
+	persisted := 0
+
+	return helper.Map(actions, func(action strategy.Action) strategy.Action {
+		if action != strategy.Hold && action != desired {
+			desired = action
+			persisted = 0
+		}
+		if desired == strategy.Hold {
+			return strategy.Hold
+		}
+		persisted++
+
+		if desired != position && persisted >= f.Bars {
+			position = desired
+			return position
+		}
+		return strategy.Hold
+	})
+}
+
+// Name returns the name of the filter.
+func (f *CooldownFilter) Name() string {
+	return fmt.Sprintf("Cooldown %d", f.Bars)
+}
+
+// Filter processes the provided actions.
+func (f *CooldownFilter) Filter(actions <-chan strategy.Action) <-chan strategy.Action {
+	desired := strategy.Sell
+	position := strategy.Sell
+	since := f.Bars
+
+	return helper.Map(actions, func(action strategy.Action) strategy.Action {
+		since++
+		if action != strategy.Hold {
+			desired = action
+		}
+		if desired == position || since <= f.Bars {
+			return strategy.Hold
+		}
+		position = desired
+		since = 0
+		return position
+	})
+}
@@ -0,0 +1,77 @@
+package decorator
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+)
+
+func TestActionFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   ActionFilter
+		actions  []strategy.Action
+		expected []strategy.Action
+	}{
+		{
+			"sells held until the minimum period",
+			NewMinimumHoldFilter(2),
+			[]strategy.Action{B, S, H, H, S, B, H, S, H},
+			[]strategy.Action{B, H, S, H, H, B, H, S, H},
+		},
+		{
+			"held sell cancelled by a buy",
+			NewMinimumHoldFilter(3),
+			[]strategy.Action{B, S, B, H, H},
+			[]strategy.Action{B, H, H, H, H},
+		},
+		{
+			"signals acted on once persisted",
+			NewPersistenceFilter(2),
+			[]strategy.Action{B, H, H, S, B, H, S, H, H},
+			[]strategy.Action{H, B, H, H, H, H, H, S, H},
+		},
+		{
+			"signals persisting for one bar",
+			NewPersistenceFilter(1),
+			[]strategy.Action{B, S, H},
+			[]strategy.Action{B, S, H},
+		},
+		{
+			"reversals deferred until the cooldown has passed",
+			NewCooldownFilter(2),
+			[]strategy.Action{B, S, H, H, H, B, H, S, B, H, H, H},
+			[]strategy.Action{B, H, H, S, H, H, B, H, H, H, H, H},
+		},
+		{
+			"no cooldown",
+			NewCooldownFilter(0),
+			[]strategy.Action{B, S, B},
+			[]strategy.Action{B, S, B},
+		},
+	}
+	for _, test := range tests {
+		actual := helper.ChanToSlice(test.filter.Filter(helper.SliceToChan(test.actions)))
+		if !slices.Equal(actual, test.expected) {
+			t.Fatalf("%s: actual %v expected %v", test.name, actual, test.expected)
+		}
+	}
+}
+
+func TestActionFilterNames(t *testing.T) {
+	tests := []struct {
+		filter ActionFilter
+		name   string
+	}{
+		{NewMinimumHoldFilter(5), "Min Hold 5"},
+		{NewPersistenceFilter(2), "Persist 2"},
+		{NewCooldownFilter(3), "Cooldown 3"},
+	}
+	for _, test := range tests {
+		if actual := test.filter.Name(); actual != test.name {
+			t.Fatalf("actual %q expected %q", actual, test.name)
+		}
+	}
+}
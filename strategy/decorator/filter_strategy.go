@@ -0,0 +1,100 @@
+package decorator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/vextasy/strategise/internal"
+	"github.com/vextasy/strategise/strategy/warmup"
+)
+
+// FilterStrategy wraps an inner strategy and passes its actions through
+// a chain of action filters.
+type FilterStrategy struct {
+	strategy.Strategy
+
+	// Inner is the strategy whose actions are filtered.
+	Inner strategy.Strategy
+
+	// Filters are applied to the inner actions in order.
+	Filters []ActionFilter
+}
+
+// NewFilterStrategy function initializes a strategy applying the given filters to the inner strategy.
+func NewFilterStrategy(inner strategy.Strategy, filters ...ActionFilter) *FilterStrategy {
+	return &FilterStrategy{
+		Inner:   inner,
+		Filters: filters,
+	}
+}
+
+// NewMinimumHoldStrategy function initializes a strategy enforcing a minimum holding period.
+func NewMinimumHoldStrategy(inner strategy.Strategy, bars int) *FilterStrategy {
+	return NewFilterStrategy(inner, NewMinimumHoldFilter(bars))
+}
+
+// NewPersistenceStrategy function initializes a strategy requiring signals to persist.
+func NewPersistenceStrategy(inner strategy.Strategy, bars int) *FilterStrategy {
+	return NewFilterStrategy(inner, NewPersistenceFilter(bars))
+}
+
+// NewCooldownStrategy function initializes a strategy ignoring reversals within a cooldown.
+func NewCooldownStrategy(inner strategy.Strategy, bars int) *FilterStrategy {
+	return NewFilterStrategy(inner, NewCooldownFilter(bars))
+}
+
+// Name returns the name of the strategy.
+func (s *FilterStrategy) Name() string {
+	names := make([]string, len(s.Filters))
+	for i, f := range s.Filters {
+		names[i] = f.Name()
+	}
+	return fmt.Sprintf("%s Filtered (%s)", s.Inner.Name(), strings.Join(names, ", "))
+}
+
+// IdlePeriod is the number of snapshots consumed before the first meaningful action.
+func (s *FilterStrategy) IdlePeriod() int {
+	return warmup.IdlePeriod(s.Inner)
+}
+
+// Compute processes the provided asset snapshots and generates a
+// stream of actionable recommendations.
+func (s *FilterStrategy) Compute(snapshots <-chan *asset.Snapshot) <-chan strategy.Action {
+	actions := strategy.NormalizeActions(s.Inner.Compute(snapshots))
+	for _, f := range s.Filters {
+		actions = f.Filter(actions)
+	}
+	return actions
+}
+
+// Report processes the provided asset snapshots and generates a
+// report annotated with both the filtered and the unfiltered actions.
+func (s *FilterStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
+	snapshots := internal.NewSeriesFromChan(c)
+
+	dates := asset.SnapshotsAsDates(snapshots.Chan())
+	closings := asset.SnapshotsAsClosings(snapshots.Chan())
+
+	innerActions := strategy.NormalizeActions(s.Inner.Compute(snapshots.Chan()))
+	innerAnnotations := strategy.ActionsToAnnotations(innerActions)
+
+	actions, outcomes := strategy.ComputeWithOutcome(s, snapshots.Chan())
+	annotations := strategy.ActionsToAnnotations(actions)
+	outcomes = helper.MultiplyBy(outcomes, 100)
+
+	report := helper.NewReport(s.Name(), dates)
+	report.AddChart()
+
+	// The unfiltered actions share the Close chart so the dropped and
+	// delayed signals line up with the filtered ones.
+	report.AddColumn(helper.NewNumericReportColumn("Close", closings))
+	report.AddColumn(helper.NewAnnotationReportColumn(annotations), 0)
+	report.AddColumn(helper.NewAnnotationReportColumn(innerAnnotations), 0)
+
+	report.AddColumn(helper.NewNumericReportColumn("Outcome", outcomes), 1)
+
+	return report
+}
@@ -0,0 +1,25 @@
+package decorator
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+)
+
+func TestFilterStrategy(t *testing.T) {
+	// The inner actions are normalized before the filters are applied in order.
+	inner := fixedStrategy{B, B, S, S, B, H}
+	s := NewFilterStrategy(inner, NewMinimumHoldFilter(3), NewCooldownFilter(1))
+
+	if expected := "Fixed Filtered (Min Hold 3, Cooldown 1)"; s.Name() != expected {
+		t.Fatalf("actual %q expected %q", s.Name(), expected)
+	}
+
+	snapshots := newTestSnapshots(10, 11, 12, 13, 14, 15)
+	actual := helper.ChanToSlice(s.Compute(helper.SliceToChan(snapshots)))
+	if expected := []strategy.Action{B, H, H, S, H, B}; !slices.Equal(actual, expected) {
+		t.Fatalf("actual %v expected %v", actual, expected)
+	}
+}
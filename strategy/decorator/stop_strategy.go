@@ -0,0 +1,163 @@
+package decorator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/vextasy/strategise/internal"
+	"github.com/vextasy/strategise/strategy/warmup"
+)
+
+// StopStrategy wraps an inner strategy and closes its positions early when the
+// closing price reaches a stop-loss, take-profit or trailing-stop level measured
+// from the entry price. The levels are fractions of the entry price, such as 0.05
+// for 5%, and a level of zero disables that rule. Once stopped out, a new position
+// is only entered on the inner strategy's next Buy.
+type StopStrategy struct {
+	strategy.Strategy
+
+	// Inner is the strategy whose actions are overridden.
+	Inner strategy.Strategy
+
+	// StopLoss sells when the price falls this fraction below the entry price.
+	StopLoss float64
+
+	// TakeProfit sells when the price rises this fraction above the entry price.
+	TakeProfit float64
+
+	// TrailingStop sells when the price falls this fraction below the highest close since entry.
+	TrailingStop float64
+}
+
+// stopEvent is an action together with the stop rule, if any, that triggered it.
+type stopEvent struct {
+	action  strategy.Action
+	trigger string
+}
+
+// NewStopLossStrategy function initializes a strategy with a fixed stop-loss.
+func NewStopLossStrategy(inner strategy.Strategy, stopLoss float64) *StopStrategy {
+	return NewStopStrategyWith(inner, stopLoss, 0, 0)
+}
+
+// NewTakeProfitStrategy function initializes a strategy with a fixed take-profit.
+func NewTakeProfitStrategy(inner strategy.Strategy, takeProfit float64) *StopStrategy {
+	return NewStopStrategyWith(inner, 0, takeProfit, 0)
+}
+
+// NewTrailingStopStrategy function initializes a strategy with a trailing stop.
+func NewTrailingStopStrategy(inner strategy.Strategy, trailingStop float64) *StopStrategy {
+	return NewStopStrategyWith(inner, 0, 0, trailingStop)
+}
+
+// NewStopStrategyWith function initializes a strategy with the given stop levels.
+func NewStopStrategyWith(inner strategy.Strategy, stopLoss, takeProfit, trailingStop float64) *StopStrategy {
+	return &StopStrategy{
+		Inner:        inner,
+		StopLoss:     stopLoss,
+		TakeProfit:   takeProfit,
+		TrailingStop: trailingStop,
+	}
+}
+
+// Name returns the name of the strategy.
+func (s *StopStrategy) Name() string {
+	var rules []string
+	if s.StopLoss > 0 {
+		rules = append(rules, fmt.Sprintf("SL %.1f%%", s.StopLoss*100))
+	}
+	if s.TakeProfit > 0 {
+		rules = append(rules, fmt.Sprintf("TP %.1f%%", s.TakeProfit*100))
+	}
+	if s.TrailingStop > 0 {
+		rules = append(rules, fmt.Sprintf("TS %.1f%%", s.TrailingStop*100))
+	}
+	return fmt.Sprintf("%s Stops (%s)", s.Inner.Name(), strings.Join(rules, ", "))
+}
+
+// IdlePeriod is the number of snapshots consumed before the first meaningful action.
+func (s *StopStrategy) IdlePeriod() int {
+	return warmup.IdlePeriod(s.Inner)
+}
+
+// Compute processes the provided asset snapshots and generates a
+// stream of actionable recommendations.
+func (s *StopStrategy) Compute(snapshots <-chan *asset.Snapshot) <-chan strategy.Action {
+	return helper.Map(s.events(snapshots), func(e stopEvent) strategy.Action {
+		return e.action
+	})
+}
+
+// events applies the stop rules to the inner strategy's actions.
+func (s *StopStrategy) events(c <-chan *asset.Snapshot) <-chan stopEvent {
+	snapshots := internal.NewSeriesFromChan(c)
+
+	closings := asset.SnapshotsAsClosings(snapshots.Chan())
+	actions := strategy.NormalizeActions(s.Inner.Compute(snapshots.Chan()))
+
+	invested := false
+	var entry, peak float64
+
+	return helper.Operate(closings, actions, func(closing float64, action strategy.Action) stopEvent {
+		if !invested {
+			if action == strategy.Buy {
+				invested = true
+				entry, peak = closing, closing
+				return stopEvent{action: strategy.Buy}
+			}
+			return stopEvent{action: strategy.Hold}
+		}
+
+		if action == strategy.Sell {
+			invested = false
+			return stopEvent{action: strategy.Sell}
+		}
+
+		peak = max(peak, closing)
+		trigger := ""
+		switch {
+		case s.StopLoss > 0 && closing <= entry*(1-s.StopLoss):
+			trigger = "SL"
+		case s.TakeProfit > 0 && closing >= entry*(1+s.TakeProfit):
+			trigger = "TP"
+		case s.TrailingStop > 0 && closing <= peak*(1-s.TrailingStop):
+			trigger = "TS"
+		}
+		if trigger != "" {
+			invested = false
+			return stopEvent{action: strategy.Sell, trigger: trigger}
+		}
+		return stopEvent{action: strategy.Hold}
+	})
+}
+
+// Report processes the provided asset snapshots and generates a
+// report annotated with the recommended actions and the triggered stops.
+func (s *StopStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
+	snapshots := internal.NewSeriesFromChan(c)
+
+	dates := asset.SnapshotsAsDates(snapshots.Chan())
+	closings := asset.SnapshotsAsClosings(snapshots.Chan())
+
+	stops := helper.Map(s.events(snapshots.Chan()), func(e stopEvent) string {
+		return e.trigger
+	})
+
+	actions, outcomes := strategy.ComputeWithOutcome(s, snapshots.Chan())
+	annotations := strategy.ActionsToAnnotations(actions)
+	outcomes = helper.MultiplyBy(outcomes, 100)
+
+	report := helper.NewReport(s.Name(), dates)
+	report.AddChart()
+
+	report.AddColumn(helper.NewNumericReportColumn("Close", closings))
+	report.AddColumn(helper.NewAnnotationReportColumn(annotations), 0)
+	report.AddColumn(helper.NewAnnotationReportColumn(stops), 0)
+
+	report.AddColumn(helper.NewNumericReportColumn("Outcome", outcomes), 1)
+
+	return report
+}
@@ -0,0 +1,140 @@
+package decorator
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+)
+
+const (
+	B = strategy.Buy
+	S = strategy.Sell
+	H = strategy.Hold
+)
+
+// fixedStrategy takes the same actions whatever the prices.
+type fixedStrategy []strategy.Action
+
+func (s fixedStrategy) Name() string { return "Fixed" }
+
+func (s fixedStrategy) Compute(c <-chan *asset.Snapshot) <-chan strategy.Action {
+	i := 0
+	return helper.Map(c, func(*asset.Snapshot) strategy.Action {
+		action := H
+		if i < len(s) {
+			action = s[i]
+		}
+		i++
+		return action
+	})
+}
+
+func (s fixedStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
+	return helper.NewReport(s.Name(), asset.SnapshotsAsDates(c))
+}
+
+// newTestSnapshots returns a snapshot for each closing price, one day apart.
+func newTestSnapshots(closings ...float64) []*asset.Snapshot {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := make([]*asset.Snapshot, len(closings))
+	for i, close := range closings {
+		snapshots[i] = &asset.Snapshot{Date: start.AddDate(0, 0, i), Open: close, High: close, Low: close, Close: close}
+	}
+	return snapshots
+}
+
+func TestStopStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		stops    [3]float64 // Stop-loss, take-profit and trailing stop
+		closings []float64
+		inner    fixedStrategy
+		actions  []strategy.Action
+		triggers []string
+	}{
+		{
+			"stop-loss",
+			[3]float64{0.1, 0, 0},
+			[]float64{100, 95, 89, 90, 100},
+			fixedStrategy{B},
+			[]strategy.Action{B, H, S, H, H},
+			[]string{"", "", "SL", "", ""},
+		},
+		{
+			"take-profit",
+			[3]float64{0.1, 0.1, 0},
+			[]float64{100, 105, 111, 90},
+			fixedStrategy{B},
+			[]strategy.Action{B, H, S, H},
+			[]string{"", "", "TP", ""},
+		},
+		{
+			"trailing stop from the highest close",
+			[3]float64{0, 0, 0.1},
+			[]float64{100, 120, 110, 107, 130},
+			fixedStrategy{B},
+			[]strategy.Action{B, H, H, S, H},
+			[]string{"", "", "", "TS", ""},
+		},
+		{
+			"inner sell before any stop",
+			[3]float64{0.1, 0.1, 0.1},
+			[]float64{100, 101, 102},
+			fixedStrategy{B, H, S},
+			[]strategy.Action{B, H, S},
+			[]string{"", "", ""},
+		},
+		{
+			"stopped out until the next inner buy",
+			[3]float64{0.1, 0, 0},
+			[]float64{100, 85, 90, 95, 80},
+			fixedStrategy{B, B, S, B, H},
+			[]strategy.Action{B, S, H, B, S},
+			[]string{"", "SL", "", "", "SL"},
+		},
+		{
+			"no stops",
+			[3]float64{0, 0, 0},
+			[]float64{100, 10, 1000},
+			fixedStrategy{B},
+			[]strategy.Action{B, H, H},
+			[]string{"", "", ""},
+		},
+	}
+	for _, test := range tests {
+		s := NewStopStrategyWith(test.inner, test.stops[0], test.stops[1], test.stops[2])
+		snapshots := newTestSnapshots(test.closings...)
+
+		actions := helper.ChanToSlice(s.Compute(helper.SliceToChan(snapshots)))
+		if !slices.Equal(actions, test.actions) {
+			t.Fatalf("%s: actual actions %v expected %v", test.name, actions, test.actions)
+		}
+		triggers := helper.ChanToSlice(helper.Map(s.events(helper.SliceToChan(snapshots)), func(e stopEvent) string {
+			return e.trigger
+		}))
+		if !slices.Equal(triggers, test.triggers) {
+			t.Fatalf("%s: actual triggers %q expected %q", test.name, triggers, test.triggers)
+		}
+	}
+}
+
+func TestStopStrategyName(t *testing.T) {
+	tests := []struct {
+		s    *StopStrategy
+		name string
+	}{
+		{NewStopLossStrategy(fixedStrategy{}, 0.05), "Fixed Stops (SL 5.0%)"},
+		{NewTakeProfitStrategy(fixedStrategy{}, 0.2), "Fixed Stops (TP 20.0%)"},
+		{NewTrailingStopStrategy(fixedStrategy{}, 0.1), "Fixed Stops (TS 10.0%)"},
+		{NewStopStrategyWith(fixedStrategy{}, 0.05, 0.2, 0.1), "Fixed Stops (SL 5.0%, TP 20.0%, TS 10.0%)"},
+	}
+	for _, test := range tests {
+		if actual := test.s.Name(); actual != test.name {
+			t.Fatalf("actual %q expected %q", actual, test.name)
+		}
+	}
+}
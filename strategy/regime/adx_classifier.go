@@ -0,0 +1,133 @@
+package regime
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+)
+
+const (
+	// DefaultAdxClassifierPeriod is the default ADX period.
+	DefaultAdxClassifierPeriod = 14
+
+	// DefaultAdxClassifierThreshold is the default ADX level at or above which the market is trending.
+	DefaultAdxClassifierThreshold = 25
+)
+
+// AdxClassifier classifies the market as trending when the Average
+// Directional Index (ADX) is at or above a threshold.
+//
+//	TR = Max(High - Low, |High - Previous Close|, |Low - Previous Close|)
+//	+DI = 100 * Wilder(+DM) / Wilder(TR)
+//	-DI = 100 * Wilder(-DM) / Wilder(TR)
+//	DX = 100 * |+DI - -DI| / (+DI + -DI)
+//	ADX = Wilder(DX)
+type AdxClassifier struct {
+	// Period is the smoothing period.
+	Period int
+
+	// Threshold is the ADX level at or above which the market is trending.
+	Threshold float64
+}
+
+// NewAdxClassifier function initializes a new ADX classifier instance.
+func NewAdxClassifier() *AdxClassifier {
+	return NewAdxClassifierWith(DefaultAdxClassifierPeriod, DefaultAdxClassifierThreshold)
+}
+
+// NewAdxClassifierWith function initializes a new ADX classifier instance with the given parameters.
+func NewAdxClassifierWith(period int, threshold float64) *AdxClassifier {
+	return &AdxClassifier{
+		Period:    period,
+		Threshold: threshold,
+	}
+}
+
+// Name returns the name of the classifier.
+func (a *AdxClassifier) Name() string {
+	return fmt.Sprintf("ADX %d>%.0f", a.Period, a.Threshold)
+}
+
+// IdlePeriod is the number of snapshots before the first ADX value.
+func (a *AdxClassifier) IdlePeriod() int {
+	return 2*a.Period - 1
+}
+
+// Classify processes the provided asset snapshots and generates one regime for each of them.
+func (a *AdxClassifier) Classify(snapshots <-chan *asset.Snapshot) <-chan Regime {
+	return helper.Map(a.Compute(snapshots), func(adx float64) Regime {
+		switch {
+		case math.IsNaN(adx):
+			return Unknown
+		case adx >= a.Threshold:
+			return Trending
+		}
+		return MeanReverting
+	})
+}
+
+// Compute processes the provided asset snapshots and generates the ADX values.
+// Values during the idle period are NaN.
+func (a *AdxClassifier) Compute(snapshots <-chan *asset.Snapshot) <-chan float64 {
+	period := float64(a.Period)
+
+	var previous *asset.Snapshot
+	var tr, plusDm, minusDm, adx float64
+	count := 0
+	dxCount := 0
+
+	return helper.Map(snapshots, func(s *asset.Snapshot) float64 {
+		defer func() { previous = s }()
+		if previous == nil {
+			return math.NaN()
+		}
+		count++
+
+		up := s.High - previous.High
+		down := previous.Low - s.Low
+		plus, minus := 0.0, 0.0
+		if up > down && up > 0 {
+			plus = up
+		}
+		if down > up && down > 0 {
+			minus = down
+		}
+		trueRange := max(s.High-s.Low, math.Abs(s.High-previous.Close), math.Abs(s.Low-previous.Close))
+
+		// Wilder smoothing starts from the sum of the first period values.
+		if count <= a.Period {
+			tr += trueRange
+			plusDm += plus
+			minusDm += minus
+			if count < a.Period {
+				return math.NaN()
+			}
+		} else {
+			tr = tr - tr/period + trueRange
+			plusDm = plusDm - plusDm/period + plus
+			minusDm = minusDm - minusDm/period + minus
+		}
+
+		dx := 0.0
+		if tr > 0 {
+			plusDi := 100 * plusDm / tr
+			minusDi := 100 * minusDm / tr
+			if plusDi+minusDi > 0 {
+				dx = 100 * math.Abs(plusDi-minusDi) / (plusDi + minusDi)
+			}
+		}
+
+		dxCount++
+		if dxCount <= a.Period {
+			adx += dx / period
+			if dxCount < a.Period {
+				return math.NaN()
+			}
+			return adx
+		}
+		adx = (adx*(period-1) + dx) / period
+		return adx
+	})
+}
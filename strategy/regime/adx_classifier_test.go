@@ -0,0 +1,37 @@
+package regime
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/cinar/indicator/v2/helper"
+)
+
+func TestAdxClassifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		closings []float64
+		adx      []float64
+		expected []Regime
+	}{
+		{"rising", []float64{10, 11, 12, 13, 14}, []float64{math.NaN(), math.NaN(), math.NaN(), 100, 100}, []Regime{U, U, U, T, T}},
+		{"flat", []float64{10, 10, 10, 10}, []float64{math.NaN(), math.NaN(), math.NaN(), 0}, []Regime{U, U, U, M}},
+	}
+	a := NewAdxClassifierWith(2, 25)
+	for _, test := range tests {
+		adx := helper.ChanToSlice(a.Compute(helper.SliceToChan(newTestSnapshots(test.closings...))))
+		if !slices.EqualFunc(adx, test.adx, func(x, y float64) bool {
+			return x == y || math.IsNaN(x) && math.IsNaN(y)
+		}) {
+			t.Fatalf("%s: actual ADX %v expected %v", test.name, adx, test.adx)
+		}
+		actual := helper.ChanToSlice(a.Classify(helper.SliceToChan(newTestSnapshots(test.closings...))))
+		if !slices.Equal(actual, test.expected) {
+			t.Fatalf("%s: actual %v expected %v", test.name, actual, test.expected)
+		}
+	}
+	if a.IdlePeriod() != 3 {
+		t.Fatalf("actual idle period %d expected 3", a.IdlePeriod())
+	}
+}
@@ -0,0 +1,61 @@
+package regime
+
+import (
+	"github.com/cinar/indicator/v2/asset"
+)
+
+// Regime is the market regime of a security on a given day.
+type Regime int
+
+const (
+	// Unknown is the regime before a classifier has enough data.
+	Unknown Regime = iota
+
+	// MeanReverting is a sideways market suited to oscillator strategies.
+	MeanReverting
+
+	// Trending is a directional market suited to trend following strategies.
+	Trending
+)
+
+// String returns the name of the regime.
+func (r Regime) String() string {
+	switch r {
+	case MeanReverting:
+		return "Mean Reverting"
+	case Trending:
+		return "Trending"
+	}
+	return "Unknown"
+}
+
+// Classifier classifies each snapshot of a security into a market regime.
+type Classifier interface {
+	// Name returns the name of the classifier.
+	Name() string
+
+	// Classify processes the provided asset snapshots and generates one regime for each of them.
+	Classify(snapshots <-chan *asset.Snapshot) <-chan Regime
+
+	// IdlePeriod is the number of snapshots classified as Unknown before the classifier has enough data.
+	IdlePeriod() int
+}
+
+// window keeps the most recent values up to a fixed size.
+type window struct {
+	size   int
+	values []float64
+}
+
+// push adds a value, dropping the oldest one once the window is full.
+func (w *window) push(value float64) {
+	w.values = append(w.values, value)
+	if len(w.values) > w.size {
+		w.values = w.values[1:]
+	}
+}
+
+// full reports whether the window holds size values.
+func (w *window) full() bool {
+	return len(w.values) == w.size
+}
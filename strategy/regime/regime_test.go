@@ -0,0 +1,55 @@
+package regime
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+)
+
+// newTestSnapshots returns a snapshot for each closing price, one day apart.
+func newTestSnapshots(closings ...float64) []*asset.Snapshot {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := make([]*asset.Snapshot, len(closings))
+	for i, close := range closings {
+		snapshots[i] = &asset.Snapshot{Date: start.AddDate(0, 0, i), Open: close, High: close, Low: close, Close: close}
+	}
+	return snapshots
+}
+
+const (
+	U = Unknown
+	M = MeanReverting
+	T = Trending
+)
+
+func TestRegimeString(t *testing.T) {
+	tests := []struct {
+		regime   Regime
+		expected string
+	}{
+		{Unknown, "Unknown"},
+		{MeanReverting, "Mean Reverting"},
+		{Trending, "Trending"},
+		{Regime(9), "Unknown"},
+	}
+	for _, test := range tests {
+		if actual := test.regime.String(); actual != test.expected {
+			t.Fatalf("actual %q expected %q", actual, test.expected)
+		}
+	}
+}
+
+func TestWindow(t *testing.T) {
+	w := &window{size: 2}
+	w.push(1)
+	if w.full() {
+		t.Fatal("expected a window of one value not to be full")
+	}
+	w.push(2)
+	w.push(3)
+	if !w.full() || !slices.Equal(w.values, []float64{2, 3}) {
+		t.Fatalf("actual values %v expected the latest two", w.values)
+	}
+}
@@ -0,0 +1,97 @@
+package regime
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+)
+
+const (
+	// DefaultSlopeClassifierPeriod is the default moving average period.
+	DefaultSlopeClassifierPeriod = 50
+
+	// DefaultSlopeClassifierLookback is the default number of days over which the slope is measured.
+	DefaultSlopeClassifierLookback = 10
+
+	// DefaultSlopeClassifierThreshold is the default relative change at or above which the market is trending.
+	DefaultSlopeClassifierThreshold = 0.02
+)
+
+// SlopeClassifier classifies the market as trending when its simple moving
+// average has changed by at least a threshold fraction over the lookback.
+type SlopeClassifier struct {
+	// Period is the moving average period.
+	Period int
+
+	// Lookback is the number of days over which the slope is measured.
+	Lookback int
+
+	// Threshold is the relative change, such as 0.02 for 2%, at or above which the market is trending.
+	Threshold float64
+}
+
+// NewSlopeClassifier function initializes a new moving average slope classifier instance.
+func NewSlopeClassifier() *SlopeClassifier {
+	return NewSlopeClassifierWith(
+		DefaultSlopeClassifierPeriod,
+		DefaultSlopeClassifierLookback,
+		DefaultSlopeClassifierThreshold,
+	)
+}
+
+// NewSlopeClassifierWith function initializes a new moving average slope classifier instance with the given parameters.
+func NewSlopeClassifierWith(period, lookback int, threshold float64) *SlopeClassifier {
+	return &SlopeClassifier{
+		Period:    period,
+		Lookback:  lookback,
+		Threshold: threshold,
+	}
+}
+
+// Name returns the name of the classifier.
+func (c *SlopeClassifier) Name() string {
+	return fmt.Sprintf("SMA %d Slope %d>%g", c.Period, c.Lookback, c.Threshold)
+}
+
+// IdlePeriod is the number of snapshots before the first slope value.
+func (c *SlopeClassifier) IdlePeriod() int {
+	return c.Period - 1 + c.Lookback
+}
+
+// Classify processes the provided asset snapshots and generates one regime for each of them.
+func (c *SlopeClassifier) Classify(snapshots <-chan *asset.Snapshot) <-chan Regime {
+	closings := &window{size: c.Period}
+	averages := &window{size: c.Lookback + 1}
+
+	return helper.Map(snapshots, func(s *asset.Snapshot) Regime {
+		closings.push(s.Close)
+		if !closings.full() {
+			return Unknown
+		}
+		averages.push(mean(closings.values))
+		if !averages.full() {
+			return Unknown
+		}
+
+		first := averages.values[0]
+		last := averages.values[len(averages.values)-1]
+		if first == 0 {
+			return Unknown
+		}
+		if math.Abs(last/first-1) >= c.Threshold {
+			return Trending
+		}
+		return MeanReverting
+	})
+}
+
+// mean returns the arithmetic mean of the values.
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
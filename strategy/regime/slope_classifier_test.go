@@ -0,0 +1,32 @@
+package regime
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cinar/indicator/v2/helper"
+)
+
+func TestSlopeClassifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		closings []float64
+		expected []Regime
+	}{
+		{"flat then rising", []float64{10, 10, 10, 12, 12, 12.5}, []Regime{U, U, M, T, M, M}},
+		{"zero average", []float64{0, 0, 0}, []Regime{U, U, U}},
+	}
+	c := NewSlopeClassifierWith(2, 1, 0.1)
+	for _, test := range tests {
+		actual := helper.ChanToSlice(c.Classify(helper.SliceToChan(newTestSnapshots(test.closings...))))
+		if !slices.Equal(actual, test.expected) {
+			t.Fatalf("%s: actual %v expected %v", test.name, actual, test.expected)
+		}
+	}
+	if c.IdlePeriod() != 2 {
+		t.Fatalf("actual idle period %d expected 2", c.IdlePeriod())
+	}
+	if expected := "SMA 50 Slope 10>0.02"; NewSlopeClassifier().Name() != expected {
+		t.Fatalf("actual %q expected %q", NewSlopeClassifier().Name(), expected)
+	}
+}
@@ -0,0 +1,135 @@
+package regime
+
+import (
+	"fmt"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/cinar/indicator/v2/strategy/momentum"
+	"github.com/cinar/indicator/v2/strategy/trend"
+	"github.com/vextasy/strategise/internal"
+	"github.com/vextasy/strategise/strategy/warmup"
+)
+
+// SwitchingStrategy delegates to a trend following strategy while the
+// classifier finds the market trending, and to a mean reversion strategy
+// while it finds the market mean reverting. When the regime changes, the
+// position is brought in line with the newly active strategy.
+type SwitchingStrategy struct {
+	strategy.Strategy
+
+	// Classifier determines the market regime.
+	Classifier Classifier
+
+	// TrendStrategy is followed in a trending market.
+	TrendStrategy strategy.Strategy
+
+	// MeanReversionStrategy is followed in a mean reverting market.
+	MeanReversionStrategy strategy.Strategy
+}
+
+// NewSwitchingStrategy function initializes a new regime switching strategy instance
+// switching between MACD and RSI on the ADX.
+func NewSwitchingStrategy() *SwitchingStrategy {
+	return NewSwitchingStrategyWith(
+		NewAdxClassifier(),
+		trend.NewMacdStrategy(),
+		momentum.NewRsiStrategy(),
+	)
+}
+
+// NewSwitchingStrategyWith function initializes a new regime switching strategy instance with the given parameters.
+func NewSwitchingStrategyWith(classifier Classifier, trendStrategy, meanReversionStrategy strategy.Strategy) *SwitchingStrategy {
+	return &SwitchingStrategy{
+		Classifier:            classifier,
+		TrendStrategy:         trendStrategy,
+		MeanReversionStrategy: meanReversionStrategy,
+	}
+}
+
+// Name returns the name of the strategy.
+func (s *SwitchingStrategy) Name() string {
+	return fmt.Sprintf("Regime Switching Strategy (%s, %s, %s)",
+		s.Classifier.Name(),
+		s.TrendStrategy.Name(),
+		s.MeanReversionStrategy.Name(),
+	)
+}
+
+// IdlePeriod is the number of snapshots consumed before the first meaningful action.
+func (s *SwitchingStrategy) IdlePeriod() int {
+	return max(s.Classifier.IdlePeriod(), warmup.Max(s.TrendStrategy, s.MeanReversionStrategy))
+}
+
+// Compute processes the provided asset snapshots and generates a
+// stream of actionable recommendations.
+func (s *SwitchingStrategy) Compute(c <-chan *asset.Snapshot) <-chan strategy.Action {
+	snapshots := internal.NewSeriesFromChan(c)
+
+	regimes := s.Classifier.Classify(snapshots.Chan())
+	trends := strategy.DenormalizeActions(s.TrendStrategy.Compute(snapshots.Chan()))
+	meanReversions := strategy.DenormalizeActions(s.MeanReversionStrategy.Compute(snapshots.Chan()))
+
+	// The desired position is that of the strategy active in the current regime.
+	desired := helper.Operate(regimes, helper.Operate(trends, meanReversions, func(t, m strategy.Action) [2]strategy.Action {
+		return [2]strategy.Action{t, m}
+	}), func(regime Regime, actions [2]strategy.Action) strategy.Action {
+		switch regime {
+		case Trending:
+			return actions[0]
+		case MeanReverting:
+			return actions[1]
+		}
+		return strategy.Hold
+	})
+
+	return strategy.NormalizeActions(desired)
+}
+
+// Report processes the provided asset snapshots and generates a
+// report annotated with the recommended actions and the regime changes.
+// helper.Report cannot shade the background, so rather than plotting the
+// regime as a series, the first day of each regime span is annotated on the
+// Close chart with T for trending or M for mean reverting.
+func (s *SwitchingStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
+	snapshots := internal.NewSeriesFromChan(c)
+
+	dates := asset.SnapshotsAsDates(snapshots.Chan())
+	closings := asset.SnapshotsAsClosings(snapshots.Chan())
+	regimes := regimeAnnotations(s.Classifier.Classify(snapshots.Chan()))
+
+	actions, outcomes := strategy.ComputeWithOutcome(s, snapshots.Chan())
+	annotations := strategy.ActionsToAnnotations(actions)
+	outcomes = helper.MultiplyBy(outcomes, 100)
+
+	report := helper.NewReport(s.Name(), dates)
+	report.AddChart()
+
+	report.AddColumn(helper.NewNumericReportColumn("Close", closings))
+	report.AddColumn(helper.NewAnnotationReportColumn(annotations), 0)
+	report.AddColumn(helper.NewAnnotationReportColumn(regimes), 0)
+
+	report.AddColumn(helper.NewNumericReportColumn("Outcome", outcomes), 1)
+
+	return report
+}
+
+// regimeAnnotations returns T or M on the first day of each trending or
+// mean reverting span, and no annotation on the other days.
+func regimeAnnotations(regimes <-chan Regime) <-chan string {
+	previous := Unknown
+	return helper.Map(regimes, func(r Regime) string {
+		defer func() { previous = r }()
+		if r == previous {
+			return ""
+		}
+		switch r {
+		case Trending:
+			return "T"
+		case MeanReverting:
+			return "M"
+		}
+		return ""
+	})
+}
@@ -0,0 +1,82 @@
+package regime
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+)
+
+// fixedStrategy takes the same actions whatever the prices.
+type fixedStrategy []strategy.Action
+
+func (s fixedStrategy) Name() string { return "Fixed" }
+
+func (s fixedStrategy) Compute(c <-chan *asset.Snapshot) <-chan strategy.Action {
+	i := 0
+	return helper.Map(c, func(*asset.Snapshot) strategy.Action {
+		action := strategy.Hold
+		if i < len(s) {
+			action = s[i]
+		}
+		i++
+		return action
+	})
+}
+
+func (s fixedStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
+	return helper.NewReport(s.Name(), asset.SnapshotsAsDates(c))
+}
+
+// fixedClassifier finds the same regimes whatever the prices.
+type fixedClassifier []Regime
+
+func (c fixedClassifier) Name() string { return "Fixed" }
+
+func (c fixedClassifier) IdlePeriod() int { return 0 }
+
+func (c fixedClassifier) Classify(snapshots <-chan *asset.Snapshot) <-chan Regime {
+	i := 0
+	return helper.Map(snapshots, func(*asset.Snapshot) Regime {
+		regime := Unknown
+		if i < len(c) {
+			regime = c[i]
+		}
+		i++
+		return regime
+	})
+}
+
+func TestSwitchingStrategy(t *testing.T) {
+	const (
+		B = strategy.Buy
+		S = strategy.Sell
+		H = strategy.Hold
+	)
+	// The position follows the strategy active in each regime, so it is
+	// sold when mean reversion takes over and bought back when the trend does.
+	s := NewSwitchingStrategyWith(
+		fixedClassifier{U, T, T, M, M, T, T},
+		fixedStrategy{H, B},
+		fixedStrategy{B, H, S},
+	)
+	snapshots := newTestSnapshots(1, 2, 3, 4, 5, 6, 7)
+
+	actual := helper.ChanToSlice(s.Compute(helper.SliceToChan(snapshots)))
+	if expected := []strategy.Action{H, B, H, S, H, B, H}; !slices.Equal(actual, expected) {
+		t.Fatalf("actual %v expected %v", actual, expected)
+	}
+	if expected := "Regime Switching Strategy (Fixed, Fixed, Fixed)"; s.Name() != expected {
+		t.Fatalf("actual %q expected %q", s.Name(), expected)
+	}
+}
+
+func TestRegimeAnnotations(t *testing.T) {
+	regimes := []Regime{U, U, T, T, M, M, M, T}
+	actual := helper.ChanToSlice(regimeAnnotations(helper.SliceToChan(regimes)))
+	if expected := []string{"", "", "T", "", "M", "", "", "T"}; !slices.Equal(actual, expected) {
+		t.Fatalf("actual %q expected %q", actual, expected)
+	}
+}
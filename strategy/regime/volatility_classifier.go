@@ -0,0 +1,103 @@
+package regime
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+)
+
+const (
+	// DefaultVolatilityClassifierPeriod is the default number of daily returns in the volatility.
+	DefaultVolatilityClassifierPeriod = 20
+
+	// DefaultVolatilityClassifierLookback is the default number of volatility values ranked.
+	DefaultVolatilityClassifierLookback = 250
+
+	// DefaultVolatilityClassifierPercentile is the default percentile at or above which the market is trending.
+	DefaultVolatilityClassifierPercentile = 0.5
+)
+
+// VolatilityClassifier classifies the market as trending when the current
+// volatility of daily returns ranks at or above a percentile of its recent
+// values, and as mean reverting while volatility is relatively low.
+type VolatilityClassifier struct {
+	// Period is the number of daily returns in the volatility.
+	Period int
+
+	// Lookback is the number of volatility values the current one is ranked against.
+	Lookback int
+
+	// Percentile, between 0 and 1, at or above which the market is trending.
+	Percentile float64
+}
+
+// NewVolatilityClassifier function initializes a new volatility percentile classifier instance.
+func NewVolatilityClassifier() *VolatilityClassifier {
+	return NewVolatilityClassifierWith(
+		DefaultVolatilityClassifierPeriod,
+		DefaultVolatilityClassifierLookback,
+		DefaultVolatilityClassifierPercentile,
+	)
+}
+
+// NewVolatilityClassifierWith function initializes a new volatility percentile classifier instance with the given parameters.
+func NewVolatilityClassifierWith(period, lookback int, percentile float64) *VolatilityClassifier {
+	return &VolatilityClassifier{
+		Period:     period,
+		Lookback:   lookback,
+		Percentile: percentile,
+	}
+}
+
+// Name returns the name of the classifier.
+func (c *VolatilityClassifier) Name() string {
+	return fmt.Sprintf("Volatility %d/%d>%.0f%%", c.Period, c.Lookback, c.Percentile*100)
+}
+
+// IdlePeriod is the number of snapshots before the first ranked volatility.
+func (c *VolatilityClassifier) IdlePeriod() int {
+	return c.Period + c.Lookback - 1
+}
+
+// Classify processes the provided asset snapshots and generates one regime for each of them.
+func (c *VolatilityClassifier) Classify(snapshots <-chan *asset.Snapshot) <-chan Regime {
+	returns := &window{size: c.Period}
+	volatilities := &window{size: c.Lookback}
+	previous := math.NaN()
+
+	return helper.Map(snapshots, func(s *asset.Snapshot) Regime {
+		defer func() { previous = s.Close }()
+		if math.IsNaN(previous) || previous == 0 {
+			return Unknown
+		}
+
+		returns.push(s.Close/previous - 1)
+		if !returns.full() {
+			return Unknown
+		}
+
+		m := mean(returns.values)
+		sum := 0.0
+		for _, r := range returns.values {
+			sum += (r - m) * (r - m)
+		}
+		volatility := math.Sqrt(sum / float64(len(returns.values)))
+		volatilities.push(volatility)
+		if !volatilities.full() {
+			return Unknown
+		}
+
+		below := 0
+		for _, v := range volatilities.values {
+			if v < volatility {
+				below++
+			}
+		}
+		if float64(below)/float64(len(volatilities.values)) >= c.Percentile {
+			return Trending
+		}
+		return MeanReverting
+	})
+}
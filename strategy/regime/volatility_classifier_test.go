@@ -0,0 +1,24 @@
+package regime
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cinar/indicator/v2/helper"
+)
+
+func TestVolatilityClassifier(t *testing.T) {
+	c := NewVolatilityClassifierWith(2, 2, 0.5)
+	snapshots := newTestSnapshots(100, 100, 100, 110, 110, 110)
+
+	actual := helper.ChanToSlice(c.Classify(helper.SliceToChan(snapshots)))
+	if expected := []Regime{U, U, U, T, M, M}; !slices.Equal(actual, expected) {
+		t.Fatalf("actual %v expected %v", actual, expected)
+	}
+	if c.IdlePeriod() != 3 {
+		t.Fatalf("actual idle period %d expected 3", c.IdlePeriod())
+	}
+	if expected := "Volatility 20/250>50%"; NewVolatilityClassifier().Name() != expected {
+		t.Fatalf("actual %q expected %q", NewVolatilityClassifier().Name(), expected)
+	}
+}
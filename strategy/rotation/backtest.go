@@ -0,0 +1,202 @@
+package rotation
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/vextasy/strategise/analysis"
+	"github.com/vextasy/strategise/internal"
+)
+
+// Backtest runs a rotation strategy over all non-retired assets in the repository.
+type Backtest struct {
+	repository asset.Repository
+	outputDir  string
+
+	// Strategy is the rotation strategy to backtest.
+	Strategy *RotationStrategy
+
+	// Names are the assets ranked. All non-retired assets when empty.
+	Names []string
+
+	// LastDays is the number of most recent days to trade. All days when zero.
+	LastDays int
+}
+
+// Result is the outcome of a rotation backtest.
+type Result struct {
+	Dates       []time.Time
+	Returns     []float64 // Daily portfolio returns
+	Rebalancing []Rebalancing
+}
+
+// NewBacktest creates a rotation backtest writing its reports to outputDir.
+func NewBacktest(repository asset.Repository, outputDir string) *Backtest {
+	return &Backtest{
+		repository: repository,
+		outputDir:  outputDir,
+		Strategy:   NewRotationStrategy(),
+	}
+}
+
+// Run backtests the strategy, writes its reports and returns the result.
+func (b *Backtest) Run() (*Result, error) {
+	if err := b.Strategy.Validate(); err != nil {
+		return nil, err
+	}
+
+	calendar, err := b.load()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.simulate(calendar.Dates, calendar.Closings)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.writeReport(result)
+	if err != nil {
+		return nil, err
+	}
+	return result, b.writeHoldings(result)
+}
+
+// load reads the closing prices of the assets onto a shared calendar.
+func (b *Backtest) load() (*analysis.Calendar, error) {
+	names := b.Names
+	if len(names) == 0 {
+		var err error
+		names, err = b.repository.Assets()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return analysis.NewCalendar(b.repository, names)
+}
+
+// simulate trades the strategy over the calendar.
+// The holdings chosen at a day's close earn the following days' returns.
+func (b *Backtest) simulate(dates []time.Time, closings map[string][]float64) (*Result, error) {
+	from := 0
+	if b.LastDays > 0 && b.LastDays < len(dates) {
+		from = len(dates) - b.LastDays
+	}
+
+	result := &Result{
+		Dates:   dates[from:],
+		Returns: make([]float64, len(dates)-from),
+	}
+
+	rebalance := max(1, b.Strategy.Rebalance)
+	var holdings []Holding
+	for i := from; i < len(dates); i++ {
+		if i > from && len(holdings) > 0 {
+			sum := 0.0
+			for _, h := range holdings {
+				closes := closings[h.Asset]
+				if closes[i-1] != 0 {
+					sum += closes[i]/closes[i-1] - 1
+				}
+			}
+			result.Returns[i-from] = sum / float64(len(holdings))
+		}
+
+		if (i-from)%rebalance == 0 {
+			var err error
+			holdings, err = b.Strategy.Rank(closings, i)
+			if err != nil {
+				return nil, err
+			}
+			result.Rebalancing = append(result.Rebalancing, Rebalancing{
+				Date:     dates[i],
+				Holdings: holdings,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// writeReport charts the portfolio equity and annotates each rebalance with its holdings.
+func (b *Backtest) writeReport(result *Result) error {
+	held := make(map[time.Time]string, len(result.Rebalancing))
+	for _, r := range result.Rebalancing {
+		names := make([]string, len(r.Holdings))
+		for i, h := range r.Holdings {
+			names[i] = h.Asset
+		}
+		held[r.Date] = strings.Join(names, ", ")
+	}
+
+	annotations := make([]string, len(result.Dates))
+	for i, date := range result.Dates {
+		annotations[i] = held[date]
+	}
+
+	cumulative := analysis.Cumulative(result.Returns)
+	total := 0.0
+	if len(cumulative) > 0 {
+		total = cumulative[len(cumulative)-1]
+	}
+
+	title := fmt.Sprintf("%s (Return %.2f%%, Max Drawdown %.2f%%)",
+		b.Strategy.Name(),
+		total,
+		analysis.MaxDrawdown(result.Returns)*100,
+	)
+
+	report := helper.NewReport(title, helper.SliceToChan(result.Dates))
+	report.AddColumn(helper.NewNumericReportColumn("Return", helper.SliceToChan(cumulative)))
+	report.AddColumn(helper.NewAnnotationReportColumn(helper.SliceToChan(annotations)), 0)
+
+	fileName := fmt.Sprintf("%s.html", internal.CleanFilename(b.Strategy.Name()))
+	return report.WriteToFile(filepath.Join(b.outputDir, fileName))
+}
+
+// holdingsTemplate lists the holdings chosen at each rebalance.
+var holdingsTemplate = template.Must(template.New("holdings").Funcs(template.FuncMap{
+	"date":    func(t time.Time) string { return t.Format(time.DateOnly) },
+	"percent": func(v float64) string { return fmt.Sprintf("%.2f", v*100) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Name }} Holdings</title>
+<style>
+table { border-collapse: collapse; font-family: sans-serif; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>{{ .Name }} Holdings</h1>
+<table>
+<tr><th>Date</th><th>Holdings (Momentum %)</th></tr>
+{{- range .Rebalancing }}
+<tr><td>{{ date .Date }}</td><td>{{ range $i, $h := .Holdings }}{{ if $i }}<br>{{ end }}{{ $h.Asset }} ({{ percent $h.Momentum }}){{ else }}Cash{{ end }}</td></tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+// writeHoldings writes the table of holdings over time.
+func (b *Backtest) writeHoldings(result *Result) error {
+	fileName := fmt.Sprintf("%s--Holdings.html", internal.CleanFilename(b.Strategy.Name()))
+	fd, err := os.Create(filepath.Join(b.outputDir, fileName))
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	return holdingsTemplate.Execute(fd, struct {
+		Name        string
+		Rebalancing []Rebalancing
+	}{b.Strategy.Name(), result.Rebalancing})
+}
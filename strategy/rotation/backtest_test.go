@@ -0,0 +1,94 @@
+package rotation
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBacktestSimulate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dates := []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2), start.AddDate(0, 0, 3)}
+	closings := map[string][]float64{
+		"A": {10, 11, 12.1, 13.31},
+		"B": {10, 10, 10, 20},
+	}
+	tests := []struct {
+		name        string
+		lastDays    int
+		returns     []float64
+		rebalancing []Rebalancing
+	}{
+		{
+			"all days",
+			0,
+			[]float64{0, 0, 0, 0.1},
+			[]Rebalancing{{Date: dates[0]}, {Date: dates[2], Holdings: []Holding{{"A", 0.1}}}},
+		},
+		{
+			"last days",
+			2,
+			[]float64{0, 0.1},
+			[]Rebalancing{{Date: dates[2], Holdings: []Holding{{"A", 0.1}}}},
+		},
+	}
+	for _, test := range tests {
+		r, err := NewRotationStrategyWith(1, 1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := NewBacktest(nil, t.TempDir())
+		b.Strategy = r
+		b.LastDays = test.lastDays
+
+		result, err := b.simulate(dates, closings)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.EqualFunc(result.Returns, test.returns, func(x, y float64) bool {
+			return math.Abs(x-y) < 1e-9
+		}) {
+			t.Fatalf("%s: actual returns %v expected %v", test.name, result.Returns, test.returns)
+		}
+		if len(result.Dates) != len(test.returns) {
+			t.Fatalf("%s: actual %d dates expected %d", test.name, len(result.Dates), len(test.returns))
+		}
+		if !slices.EqualFunc(result.Rebalancing, test.rebalancing, func(a, b Rebalancing) bool {
+			return a.Date.Equal(b.Date) && slices.EqualFunc(a.Holdings, b.Holdings, func(x, y Holding) bool {
+				return x.Asset == y.Asset && math.Abs(x.Momentum-y.Momentum) < 1e-9
+			})
+		}) {
+			t.Fatalf("%s: actual rebalancing %v expected %v", test.name, result.Rebalancing, test.rebalancing)
+		}
+	}
+}
+
+func TestBacktestWriteHoldings(t *testing.T) {
+	dir := t.TempDir()
+	b := NewBacktest(nil, dir)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := &Result{Rebalancing: []Rebalancing{
+		{Date: start},
+		{Date: start.AddDate(0, 0, 21), Holdings: []Holding{{"A&B", 0.125}, {"C", -0.05}}},
+	}}
+	if err := b.writeHoldings(result); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "Rotation_Strategy_(126,_5,_21)--Holdings.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"<td>2024-01-01</td><td>Cash</td>",
+		"<td>2024-01-22</td><td>A&amp;B (12.50)<br>C (-5.00)</td>",
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Fatalf("actual holdings\n%s\nexpected %s in them", data, expected)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package rotation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultRotationLookback is the default number of days over which momentum is measured.
+	DefaultRotationLookback = 126
+
+	// DefaultRotationTop is the default number of assets held.
+	DefaultRotationTop = 5
+
+	// DefaultRotationRebalance is the default number of days between rebalances.
+	DefaultRotationRebalance = 21
+)
+
+// ErrInvalidRotation is returned when a rotation strategy has parameters it cannot rank with.
+var ErrInvalidRotation = errors.New("invalid rotation strategy")
+
+// RotationStrategy represents the configuration parameters of a relative
+// strength rotation. Every rebalance period it ranks all assets by their
+// momentum over the lookback and holds the top ones in equal weights.
+//
+//	Momentum = Close / Close Lookback Days Ago - 1
+//
+// Unlike the strategies in the indicator library it looks at all assets at
+// once, so it does not implement strategy.Strategy and is run by a Backtest.
+type RotationStrategy struct {
+	// Lookback is the number of days over which momentum is measured.
+	Lookback int
+
+	// Top is the number of assets held.
+	Top int
+
+	// Rebalance is the number of days between rebalances.
+	Rebalance int
+}
+
+// Holding is an asset held after a rebalance, together with the momentum it was ranked by.
+type Holding struct {
+	Asset    string
+	Momentum float64
+}
+
+// Rebalancing is the set of holdings chosen on a given date.
+type Rebalancing struct {
+	Date     time.Time
+	Holdings []Holding
+}
+
+// NewRotationStrategy function initializes a new rotation strategy instance.
+func NewRotationStrategy() *RotationStrategy {
+	return &RotationStrategy{
+		Lookback:  DefaultRotationLookback,
+		Top:       DefaultRotationTop,
+		Rebalance: DefaultRotationRebalance,
+	}
+}
+
+// NewRotationStrategyWith function initializes a new rotation strategy instance with the given parameters.
+// It returns an error wrapping ErrInvalidRotation if they are not valid.
+func NewRotationStrategyWith(lookback, top, rebalance int) (*RotationStrategy, error) {
+	r := &RotationStrategy{
+		Lookback:  lookback,
+		Top:       top,
+		Rebalance: rebalance,
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Name returns the name of the strategy.
+func (r *RotationStrategy) Name() string {
+	return fmt.Sprintf("Rotation Strategy (%d, %d, %d)",
+		r.Lookback,
+		r.Top,
+		r.Rebalance,
+	)
+}
+
+// Validate returns an error wrapping ErrInvalidRotation unless the lookback
+// and the number of assets held are positive.
+func (r *RotationStrategy) Validate() error {
+	if r.Lookback <= 0 {
+		return fmt.Errorf("%w: lookback %d is not positive", ErrInvalidRotation, r.Lookback)
+	}
+	if r.Top <= 0 {
+		return fmt.Errorf("%w: top %d is not positive", ErrInvalidRotation, r.Top)
+	}
+	return nil
+}
+
+// Rank returns the assets with a momentum at day i, best first, limited to the top ones.
+// closings holds, for each asset, its closing price on every day of a shared calendar,
+// with zero before its first price. It returns an error if the strategy is not valid.
+func (r *RotationStrategy) Rank(closings map[string][]float64, i int) ([]Holding, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if i < r.Lookback {
+		return nil, nil
+	}
+
+	var ranked []Holding
+	for name, closes := range closings {
+		then, now := closes[i-r.Lookback], closes[i]
+		if then == 0 || now == 0 {
+			continue
+		}
+		ranked = append(ranked, Holding{Asset: name, Momentum: now/then - 1})
+	}
+
+	sort.Slice(ranked, func(a, b int) bool {
+		if ranked[a].Momentum == ranked[b].Momentum {
+			return ranked[a].Asset < ranked[b].Asset
+		}
+		return ranked[a].Momentum > ranked[b].Momentum
+	})
+
+	if len(ranked) > r.Top {
+		ranked = ranked[:r.Top]
+	}
+	return ranked, nil
+}
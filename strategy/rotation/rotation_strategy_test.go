@@ -0,0 +1,70 @@
+package rotation
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRotationStrategyValidate(t *testing.T) {
+	tests := []struct {
+		lookback, top, rebalance int
+		valid                    bool
+	}{
+		{126, 5, 21, true},
+		{1, 1, 0, true},
+		{0, 5, 21, false},
+		{126, 0, 21, false},
+		{-1, 5, 21, false},
+	}
+	for _, test := range tests {
+		r, err := NewRotationStrategyWith(test.lookback, test.top, test.rebalance)
+		if (err == nil) != test.valid || (err != nil && !errors.Is(err, ErrInvalidRotation)) {
+			t.Fatalf("%+v: actual error %v", test, err)
+		}
+		if test.valid && r.Name() == "" {
+			t.Fatalf("%+v: expected a name", test)
+		}
+	}
+
+	// An invalid strategy built directly is rejected when ranking.
+	r := &RotationStrategy{Lookback: 0, Top: 1}
+	if _, err := r.Rank(map[string][]float64{"A": {1, 2}}, 1); !errors.Is(err, ErrInvalidRotation) {
+		t.Fatalf("actual error %v expected %v", err, ErrInvalidRotation)
+	}
+}
+
+func TestRotationStrategyRank(t *testing.T) {
+	closings := map[string][]float64{
+		"A": {10, 11, 12},
+		"B": {10, 12, 15},
+		"C": {20, 22, 24},
+		"D": {0, 10, 20}, // No price at the start of the lookback
+		"E": {10, 9, 0},  // No price at the end of the lookback
+	}
+	tests := []struct {
+		name     string
+		top      int
+		day      int
+		expected []Holding
+	}{
+		{"before the lookback", 5, 0, nil},
+		{"ties by name", 5, 1, []Holding{{"B", 0.2}, {"A", 0.1}, {"C", 0.1}, {"E", -0.1}}},
+		{"limited to the top", 2, 2, []Holding{{"D", 1}, {"B", 0.25}}},
+	}
+	for _, test := range tests {
+		r, err := NewRotationStrategyWith(1, test.top, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := r.Rank(closings, test.day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.EqualFunc(actual, test.expected, func(a, b Holding) bool {
+			return a.Asset == b.Asset && a.Momentum-b.Momentum < 1e-9 && b.Momentum-a.Momentum < 1e-9
+		}) {
+			t.Fatalf("%s: actual %v expected %v", test.name, actual, test.expected)
+		}
+	}
+}
@@ -6,23 +6,38 @@ package trend
 
 import (
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/cinar/indicator/v2/asset"
 	"github.com/cinar/indicator/v2/helper"
 	"github.com/cinar/indicator/v2/strategy"
 	"github.com/cinar/indicator/v2/trend"
+	"github.com/vextasy/strategise/internal"
 )
 
 // BoldMacdStrategy represents the configuration parameters for calculating the
 // MACD strategy. A MACD value crossing above the signal line suggests a
 // bullish trend, while crossing below the signal line indicates a
 // bearish trend.
+//
+// The crossovers can optionally be filtered by the zero line, the
+// magnitude of the MACD histogram (MACD - Signal) and its slope.
 type BoldMacdStrategy struct {
 	strategy.Strategy
 
 	// Macd represents the configuration parameters for calculating the
 	// Moving Average Convergence Divergence (MACD).
 	Macd *trend.Macd[float64]
+
+	// ZeroLine only buys while the MACD is below zero and only sells while it is above zero.
+	ZeroLine bool
+
+	// MinHistogram is the minimum magnitude of the histogram required to act.
+	MinHistogram float64
+
+	// HistogramSlope only buys while the histogram is rising and only sells while it is falling.
+	HistogramSlope bool
 }
 
 // NewBoldMacdStrategy function initializes a new MACD strategy instance.
@@ -47,13 +62,32 @@ func NewBoldMacdStrategyWith(period1, period2, period3 int) *BoldMacdStrategy {
 
 // Name returns the name of the strategy.
 func (m *BoldMacdStrategy) Name() string {
-	return fmt.Sprintf("Bold MACD Strategy (%d,%d,%d)",
+	var options []string
+	if m.ZeroLine {
+		options = append(options, "Zero")
+	}
+	if m.MinHistogram > 0 {
+		options = append(options, fmt.Sprintf("Hist %g", m.MinHistogram))
+	}
+	if m.HistogramSlope {
+		options = append(options, "Slope")
+	}
+	if len(options) > 0 {
+		options = append([]string{""}, options...)
+	}
+	return fmt.Sprintf("Bold MACD Strategy (%d,%d,%d%s)",
 		m.Macd.Ema1.Period,
 		m.Macd.Ema2.Period,
 		m.Macd.Ema3.Period,
+		strings.Join(options, ","),
 	)
 }
 
+// IdlePeriod is the number of snapshots consumed before the first meaningful action.
+func (m *BoldMacdStrategy) IdlePeriod() int {
+	return m.Macd.IdlePeriod()
+}
+
 // Compute processes the provided asset snapshots and generates a
 // stream of actionable recommendations.
 func (m *BoldMacdStrategy) Compute(snapshots <-chan *asset.Snapshot) <-chan strategy.Action {
@@ -61,14 +95,19 @@ func (m *BoldMacdStrategy) Compute(snapshots <-chan *asset.Snapshot) <-chan stra
 
 	macds, signals := m.Macd.Compute(closings)
 
+	previous := math.NaN()
 	actions := helper.Operate(macds, signals, func(macd, signal float64) strategy.Action {
+		histogram := macd - signal
+		slope := histogram - previous
+		previous = histogram
+
 		// A MACD value crossing above signal line suggests a bullish trend.
-		if macd > signal /*&& (macd < 0)*/ {
+		if macd > signal && m.confirms(strategy.Buy, macd, histogram, slope) {
 			return strategy.Buy
 		}
 
 		// A MACD value crossing below signal line suggests a bearish trend.
-		if signal > macd /*&& (macd > 0)*/ {
+		if signal > macd && m.confirms(strategy.Sell, macd, histogram, slope) {
 			return strategy.Sell
 		}
 
@@ -83,39 +122,88 @@ func (m *BoldMacdStrategy) Compute(snapshots <-chan *asset.Snapshot) <-chan stra
 	return actions
 }
 
+// confirms applies the optional zero line, histogram magnitude and
+// histogram slope conditions to a Buy or Sell action.
+func (m *BoldMacdStrategy) confirms(action strategy.Action, macd, histogram, slope float64) bool {
+	direction := float64(action)
+
+	if m.ZeroLine && macd*direction >= 0 {
+		return false
+	}
+
+	if math.Abs(histogram) < m.MinHistogram {
+		return false
+	}
+
+	// The slope is NaN on the first value, which never confirms.
+	if m.HistogramSlope && !(slope*direction > 0) {
+		return false
+	}
+
+	return true
+}
+
 // Report processes the provided asset snapshots and generates a
 // report annotated with the recommended actions.
 func (m *BoldMacdStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
-	//
-	// snapshots[0] -> dates
-	// snapshots[1] -> closings[0] -> closings
-	//                 closings[1] -> macds, signals
-	// snapshots[2] -> actions     -> annotations
-	//              -> outcomes
-	//
-	snapshots := helper.Duplicate(c, 3)
-
-	dates := asset.SnapshotsAsDates(snapshots[0])
-	closings := helper.Duplicate(asset.SnapshotsAsClosings(snapshots[1]), 2)
-
-	macds, signals := m.Macd.Compute(closings[0])
-	macds = helper.Shift(macds, m.Macd.IdlePeriod(), 0)
-	signals = helper.Shift(signals, m.Macd.IdlePeriod(), 0)
-
-	actions, outcomes := strategy.ComputeWithOutcome(m, snapshots[2])
+	snapshots := internal.NewSeriesFromChan(c)
+
+	dates := asset.SnapshotsAsDates(snapshots.Chan())
+	closings := internal.NewSeriesFromChan(asset.SnapshotsAsClosings(snapshots.Chan()))
+
+	macd, signal := m.Macd.Compute(closings.Chan())
+	macds := internal.NewSeriesFromChan(helper.Shift(macd, m.Macd.IdlePeriod(), 0))
+	signals := internal.NewSeriesFromChan(helper.Shift(signal, m.Macd.IdlePeriod(), 0))
+
+	// Each enabled option is plotted as a line derived from the histogram.
+	type optionLine struct {
+		name  string
+		chart int
+		value func(float64) float64
+	}
+	var lines []optionLine
+	if m.ZeroLine {
+		lines = append(lines, optionLine{"Zero", 1, func(float64) float64 { return 0 }})
+	}
+	if m.MinHistogram > 0 {
+		lines = append(lines,
+			optionLine{"Min", 2, func(float64) float64 { return m.MinHistogram }},
+			optionLine{"-Min", 2, func(float64) float64 { return -m.MinHistogram }},
+		)
+	}
+	if m.HistogramSlope {
+		previous := math.NaN()
+		lines = append(lines, optionLine{"Slope", 2, func(histogram float64) float64 {
+			slope := histogram - previous
+			previous = histogram
+			return slope
+		}})
+	}
+
+	histograms := internal.NewSeriesFromChan(helper.Operate(macds.Chan(), signals.Chan(), func(macd, signal float64) float64 {
+		return macd - signal
+	}))
+
+	actions, outcomes := strategy.ComputeWithOutcome(m, snapshots.Chan())
 	annotations := strategy.ActionsToAnnotations(actions)
 	outcomes = helper.MultiplyBy(outcomes, 100)
 
 	report := helper.NewReport(m.Name(), dates)
-	report.AddChart()
-	report.AddChart()
+	report.AddChart() // MACD
+	report.AddChart() // Histogram
+	report.AddChart() // Outcome
 
-	report.AddColumn(helper.NewNumericReportColumn("Close", closings[1]))
-	report.AddColumn(helper.NewNumericReportColumn("MACD", macds), 1)
-	report.AddColumn(helper.NewNumericReportColumn("Signal", signals), 1)
+	report.AddColumn(helper.NewNumericReportColumn("Close", closings.Chan()))
+	report.AddColumn(helper.NewNumericReportColumn("MACD", macds.Chan()), 1)
+	report.AddColumn(helper.NewNumericReportColumn("Signal", signals.Chan()), 1)
 	report.AddColumn(helper.NewAnnotationReportColumn(annotations), 0, 1)
 
-	report.AddColumn(helper.NewNumericReportColumn("Outcome", outcomes), 2)
+	report.AddColumn(helper.NewNumericReportColumn("Histogram", histograms.Chan()), 2)
+	for _, line := range lines {
+		report.AddColumn(helper.NewNumericReportColumn(line.name, helper.Map(histograms.Chan(), line.value)), line.chart)
+	}
+
+	report.AddColumn(helper.NewNumericReportColumn("Outcome", outcomes), 3)
 
 	return report
 }
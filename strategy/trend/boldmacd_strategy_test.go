@@ -0,0 +1,66 @@
+package trend
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/vextasy/strategise/internal"
+)
+
+func TestBoldMacdStrategyName(t *testing.T) {
+	tests := []struct {
+		change func(m *BoldMacdStrategy)
+		name   string
+	}{
+		{func(m *BoldMacdStrategy) {}, "Bold MACD Strategy (12,26,9)"},
+		{func(m *BoldMacdStrategy) { m.ZeroLine = true }, "Bold MACD Strategy (12,26,9,Zero)"},
+		{func(m *BoldMacdStrategy) { m.MinHistogram = 0.5 }, "Bold MACD Strategy (12,26,9,Hist 0.5)"},
+		{func(m *BoldMacdStrategy) {
+			m.ZeroLine = true
+			m.MinHistogram = 0.25
+			m.HistogramSlope = true
+		}, "Bold MACD Strategy (12,26,9,Zero,Hist 0.25,Slope)"},
+	}
+	for _, test := range tests {
+		m := NewBoldMacdStrategy()
+		test.change(m)
+		if actual := m.Name(); actual != test.name {
+			t.Fatalf("actual %q expected %q", actual, test.name)
+		}
+		// The name is used in report filenames.
+		if filename := internal.ReportFilename("Asset", m.Name()); strings.ContainsAny(filename, `<>:"\|?*`) {
+			t.Fatalf("actual filename %q", filename)
+		}
+	}
+}
+
+func TestBoldMacdStrategyConfirms(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name                   string
+		change                 func(m *BoldMacdStrategy)
+		action                 strategy.Action
+		macd, histogram, slope float64
+		expected               bool
+	}{
+		{"no options", func(m *BoldMacdStrategy) {}, strategy.Buy, 1, 0.1, nan, true},
+		{"buy below zero", func(m *BoldMacdStrategy) { m.ZeroLine = true }, strategy.Buy, -0.5, 0.1, 0, true},
+		{"buy above zero", func(m *BoldMacdStrategy) { m.ZeroLine = true }, strategy.Buy, 0.5, 0.1, 0, false},
+		{"sell above zero", func(m *BoldMacdStrategy) { m.ZeroLine = true }, strategy.Sell, 0.5, -0.1, 0, true},
+		{"small histogram", func(m *BoldMacdStrategy) { m.MinHistogram = 0.5 }, strategy.Buy, 1, 0.3, 0, false},
+		{"large negative histogram", func(m *BoldMacdStrategy) { m.MinHistogram = 0.5 }, strategy.Sell, 1, -0.6, 0, true},
+		{"first slope", func(m *BoldMacdStrategy) { m.HistogramSlope = true }, strategy.Buy, 1, 0.1, nan, false},
+		{"rising buy", func(m *BoldMacdStrategy) { m.HistogramSlope = true }, strategy.Buy, 1, 0.1, 0.1, true},
+		{"rising sell", func(m *BoldMacdStrategy) { m.HistogramSlope = true }, strategy.Sell, 1, -0.1, 0.1, false},
+		{"falling sell", func(m *BoldMacdStrategy) { m.HistogramSlope = true }, strategy.Sell, 1, -0.1, -0.1, true},
+	}
+	for _, test := range tests {
+		m := NewBoldMacdStrategy()
+		test.change(m)
+		if actual := m.confirms(test.action, test.macd, test.histogram, test.slope); actual != test.expected {
+			t.Fatalf("%s: actual %v expected %v", test.name, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package volatility
+
+import (
+	"fmt"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/cinar/indicator/v2/volatility"
+	"github.com/vextasy/strategise/internal"
+)
+
+const (
+	// DefaultUlcerIndexStrategyThreshold defines the default Ulcer Index level above which Buy actions are suppressed.
+	DefaultUlcerIndexStrategyThreshold = 5
+
+	// DefaultUlcerIndexStrategySpike defines the default Ulcer Index level at which a Sell action is forced.
+	DefaultUlcerIndexStrategySpike = 10
+)
+
+// UlcerIndexStrategy represents the configuration parameters for gating other
+// strategies on drawdown risk as measured by the Ulcer Index. Buy actions are
+// suppressed while the Ulcer Index is above the threshold, and a Sell action is
+// forced when it spikes to the spike level.
+type UlcerIndexStrategy struct {
+	strategy.Strategy
+
+	// UlcerIndex represents the configuration parameters for calculating the Ulcer Index.
+	UlcerIndex *volatility.UlcerIndex[float64]
+
+	// Threshold is the level above which Buy actions are suppressed.
+	Threshold float64
+
+	// Spike is the level at or above which a Sell action is forced.
+	Spike float64
+}
+
+// NewUlcerIndexStrategy function initializes a new Ulcer Index strategy instance.
+func NewUlcerIndexStrategy() *UlcerIndexStrategy {
+	return NewUlcerIndexStrategyWith(
+		DefaultUlcerIndexStrategyThreshold,
+		DefaultUlcerIndexStrategySpike,
+	)
+}
+
+// NewUlcerIndexStrategyWith function initializes a new Ulcer Index strategy instance with the given levels.
+func NewUlcerIndexStrategyWith(threshold, spike float64) *UlcerIndexStrategy {
+	return &UlcerIndexStrategy{
+		UlcerIndex: volatility.NewUlcerIndex[float64](),
+		Threshold:  threshold,
+		Spike:      spike,
+	}
+}
+
+// Name returns the name of the strategy.
+func (u *UlcerIndexStrategy) Name() string {
+	return fmt.Sprintf("Ulcer Index Strategy (%.0f, %.0f)",
+		u.Threshold,
+		u.Spike,
+	)
+}
+
+// IdlePeriod is the number of snapshots consumed before the first meaningful action.
+func (u *UlcerIndexStrategy) IdlePeriod() int {
+	return u.UlcerIndex.IdlePeriod()
+}
+
+// Compute processes the provided asset snapshots and generates a Sell action
+// for every snapshot where the Ulcer Index is at or above the spike level.
+// It never generates a Buy action.
+func (u *UlcerIndexStrategy) Compute(snapshots <-chan *asset.Snapshot) <-chan strategy.Action {
+	return helper.Map(u.values(snapshots), func(ulcerIndex float64) strategy.Action {
+		if ulcerIndex >= u.Spike {
+			return strategy.Sell
+		}
+		return strategy.Hold
+	})
+}
+
+// Gate applies the Ulcer Index levels to the normalized actions of another
+// strategy computed from the same snapshots. The gated strategy follows the
+// position the other strategy wants to hold: an entry held back while the
+// Ulcer Index is above the threshold is taken once it falls back to the
+// threshold, if the other strategy has not sold in the meantime.
+func (u *UlcerIndexStrategy) Gate(actions <-chan strategy.Action, snapshots <-chan *asset.Snapshot) <-chan strategy.Action {
+	g := &ulcerGate{u: u}
+
+	return helper.Operate(actions, u.values(snapshots), func(action strategy.Action, ulcerIndex float64) strategy.Action {
+		gated, _ := g.next(action, ulcerIndex)
+		return gated
+	})
+}
+
+// GateAnnotations returns annotations for the interventions Gate makes in the
+// same actions: "B held" where an entry is first held back, and "S spike"
+// where a Sell is forced.
+func (u *UlcerIndexStrategy) GateAnnotations(actions <-chan strategy.Action, snapshots <-chan *asset.Snapshot) <-chan string {
+	g := &ulcerGate{u: u}
+
+	return helper.Operate(actions, u.values(snapshots), func(action strategy.Action, ulcerIndex float64) string {
+		_, annotation := g.next(action, ulcerIndex)
+		return annotation
+	})
+}
+
+// ulcerGate tracks the position a gated strategy wants against the position
+// the gate has let it take.
+type ulcerGate struct {
+	u        *UlcerIndexStrategy
+	wanted   bool // The gated strategy wants to be invested
+	invested bool // The gate has let it invest
+	held     bool // The wanted entry is being held back
+}
+
+// next returns the gated action for the next normalized action and Ulcer
+// Index, with an annotation for any intervention by the gate.
+func (g *ulcerGate) next(action strategy.Action, ulcerIndex float64) (strategy.Action, string) {
+	switch action {
+	case strategy.Buy:
+		g.wanted = true
+	case strategy.Sell:
+		g.wanted = false
+		g.held = false
+	}
+
+	switch {
+	case g.invested && !g.wanted:
+		g.invested = false
+		return strategy.Sell, ""
+
+	case g.invested && ulcerIndex >= g.u.Spike:
+		g.invested = false
+		g.held = true
+		return strategy.Sell, "S spike"
+
+	case !g.invested && g.wanted && ulcerIndex <= g.u.Threshold:
+		g.invested = true
+		g.held = false
+		return strategy.Buy, ""
+
+	case !g.invested && g.wanted && !g.held:
+		g.held = true
+		return strategy.Hold, "B held"
+	}
+	return strategy.Hold, ""
+}
+
+// values computes the Ulcer Index aligned with the snapshots.
+func (u *UlcerIndexStrategy) values(snapshots <-chan *asset.Snapshot) <-chan float64 {
+	closings := asset.SnapshotsAsClosings(snapshots)
+
+	// The Ulcer Index is treated as zero until it has a full period.
+	ulcerIndex := u.UlcerIndex.Compute(closings)
+	return helper.Shift(ulcerIndex, u.UlcerIndex.IdlePeriod(), 0)
+}
+
+// Report processes the provided asset snapshots and generates a
+// report annotated with the recommended actions.
+func (u *UlcerIndexStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
+	snapshots := internal.NewSeriesFromChan(c)
+
+	dates := asset.SnapshotsAsDates(snapshots.Chan())
+	closings := asset.SnapshotsAsClosings(snapshots.Chan())
+	ulcerIndex := internal.NewSeriesFromChan(u.values(snapshots.Chan()))
+
+	actions, outcomes := strategy.ComputeWithOutcome(u, snapshots.Chan())
+	annotations := strategy.ActionsToAnnotations(actions)
+	outcomes = helper.MultiplyBy(outcomes, 100)
+
+	report := helper.NewReport(u.Name(), dates)
+	report.AddChart()
+	report.AddChart()
+
+	report.AddColumn(helper.NewNumericReportColumn("Close", closings))
+
+	report.AddColumn(helper.NewNumericReportColumn("Ulcer", ulcerIndex.Chan()), 1)
+	report.AddColumn(helper.NewAnnotationReportColumn(annotations), 1)
+	report.AddColumn(helper.NewNumericReportColumn("Threshold", helper.Map(ulcerIndex.Chan(), func(float64) float64 {
+		return u.Threshold
+	})), 1)
+	report.AddColumn(helper.NewNumericReportColumn("Spike", helper.Map(ulcerIndex.Chan(), func(float64) float64 {
+		return u.Spike
+	})), 1)
+
+	report.AddColumn(helper.NewNumericReportColumn("Outcome", outcomes), 2)
+
+	return report
+}
@@ -0,0 +1,67 @@
+package volatility
+
+import (
+	"testing"
+
+	"github.com/cinar/indicator/v2/strategy"
+)
+
+func TestUlcerGate(t *testing.T) {
+	type step struct {
+		action     strategy.Action
+		ulcerIndex float64
+		gated      strategy.Action
+		annotation string
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			"entry held, spike and re-entry",
+			[]step{
+				{strategy.Buy, 7, strategy.Hold, "B held"},
+				{strategy.Hold, 6, strategy.Hold, ""},
+				{strategy.Hold, 5, strategy.Buy, ""},
+				{strategy.Hold, 8, strategy.Hold, ""},
+				{strategy.Hold, 12, strategy.Sell, "S spike"},
+				{strategy.Hold, 6, strategy.Hold, ""},
+				{strategy.Hold, 4, strategy.Buy, ""},
+				{strategy.Sell, 4, strategy.Sell, ""},
+				{strategy.Hold, 2, strategy.Hold, ""},
+			},
+		},
+		{
+			"held entry dropped by a sell",
+			[]step{
+				{strategy.Buy, 7, strategy.Hold, "B held"},
+				{strategy.Sell, 6, strategy.Hold, ""},
+				{strategy.Hold, 3, strategy.Hold, ""},
+				{strategy.Buy, 3, strategy.Buy, ""},
+			},
+		},
+		{
+			"spike while waiting to enter",
+			[]step{
+				{strategy.Buy, 12, strategy.Hold, "B held"},
+				{strategy.Hold, 15, strategy.Hold, ""},
+				{strategy.Hold, 1, strategy.Buy, ""},
+			},
+		},
+	}
+	for _, test := range tests {
+		g := &ulcerGate{u: NewUlcerIndexStrategy()}
+		for i, s := range test.steps {
+			gated, annotation := g.next(s.action, s.ulcerIndex)
+			if gated != s.gated || annotation != s.annotation {
+				t.Fatalf("%s: step %d: actual %v %q expected %v %q", test.name, i, gated, annotation, s.gated, s.annotation)
+			}
+		}
+	}
+}
+
+func TestUlcerIndexStrategyName(t *testing.T) {
+	if actual, expected := NewUlcerIndexStrategyWith(4, 12).Name(), "Ulcer Index Strategy (4, 12)"; actual != expected {
+		t.Fatalf("actual %q expected %q", actual, expected)
+	}
+}
@@ -0,0 +1,66 @@
+// Package warmup determines how many snapshots a strategy needs before it
+// produces its first meaningful action.
+package warmup
+
+import (
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/cinar/indicator/v2/strategy/compound"
+	"github.com/cinar/indicator/v2/strategy/momentum"
+	"github.com/cinar/indicator/v2/strategy/trend"
+	"github.com/cinar/indicator/v2/strategy/volatility"
+)
+
+// Strategy is implemented by strategies that know their own warm-up period.
+type Strategy interface {
+	strategy.Strategy
+
+	// IdlePeriod is the number of snapshots consumed before the first meaningful action.
+	IdlePeriod() int
+}
+
+// IdlePeriod returns the warm-up period of any strategy. Strategies
+// implementing Strategy report their own; the indicator library's composite
+// strategies are recursed into and its indicator based strategies use their
+// indicator's idle period. Other strategies are assumed to need no warm-up.
+func IdlePeriod(st strategy.Strategy) int {
+	switch s := st.(type) {
+	case Strategy:
+		return s.IdlePeriod()
+	case *strategy.OrStrategy:
+		return Max(s.Strategies...)
+	case *strategy.AndStrategy:
+		return Max(s.Strategies...)
+	case *strategy.MajorityStrategy:
+		return Max(s.Strategies...)
+	case *compound.MacdRsiStrategy:
+		return Max(s.MacdStrategy, s.RsiStrategy)
+	case *trend.MacdStrategy:
+		return s.Macd.IdlePeriod()
+	case *trend.AroonStrategy:
+		return s.Aroon.IdlePeriod()
+	case *trend.QstickStrategy:
+		return s.Qstick.IdlePeriod()
+	case *momentum.RsiStrategy:
+		return s.Rsi.IdlePeriod()
+	case *momentum.AwesomeOscillatorStrategy:
+		return s.AwesomeOscillator.IdlePeriod()
+	case *volatility.BollingerBandsStrategy:
+		return s.BollingerBands.IdlePeriod()
+	}
+	return 0
+}
+
+// Max returns the longest warm-up period of the given strategies.
+func Max(strategies ...strategy.Strategy) int {
+	period := 0
+	for _, st := range strategies {
+		period = max(period, IdlePeriod(st))
+	}
+	return period
+}
+
+// HasEnoughData returns true if the strategy produces at least one
+// meaningful action from the given number of snapshots.
+func HasEnoughData(st strategy.Strategy, snapshots int) bool {
+	return snapshots > IdlePeriod(st)
+}
@@ -0,0 +1,85 @@
+package warmup
+
+import (
+	"testing"
+
+	"github.com/cinar/indicator/v2/asset"
+	"github.com/cinar/indicator/v2/helper"
+	"github.com/cinar/indicator/v2/strategy"
+	"github.com/cinar/indicator/v2/strategy/compound"
+	"github.com/cinar/indicator/v2/strategy/momentum"
+	"github.com/cinar/indicator/v2/strategy/trend"
+)
+
+// holdStrategy always holds and knows nothing of its warm-up.
+type holdStrategy struct{}
+
+func (holdStrategy) Name() string { return "Hold" }
+
+func (holdStrategy) Compute(c <-chan *asset.Snapshot) <-chan strategy.Action {
+	return helper.Map(c, func(*asset.Snapshot) strategy.Action { return strategy.Hold })
+}
+
+func (s holdStrategy) Report(c <-chan *asset.Snapshot) *helper.Report {
+	return helper.NewReport(s.Name(), asset.SnapshotsAsDates(c))
+}
+
+// idleStrategy reports its own warm-up period.
+type idleStrategy struct {
+	holdStrategy
+	period int
+}
+
+func (s idleStrategy) IdlePeriod() int { return s.period }
+
+func TestIdlePeriod(t *testing.T) {
+	macd := trend.NewMacdStrategy()
+	rsi := momentum.NewRsiStrategy()
+	macdRsi := compound.NewMacdRsiStrategy()
+
+	// The composite strategies are recursed into however deeply nested.
+	majority := strategy.NewMajorityStrategy("MAJORITY")
+	majority.Strategies = []strategy.Strategy{idleStrategy{period: 7}, rsi}
+	and := strategy.NewAndStrategy("AND")
+	and.Strategies = []strategy.Strategy{holdStrategy{}, majority}
+	or := strategy.NewOrStrategy("OR")
+	or.Strategies = []strategy.Strategy{idleStrategy{period: 5}, and}
+
+	tests := []struct {
+		name     string
+		st       strategy.Strategy
+		expected int
+	}{
+		{"unknown", holdStrategy{}, 0},
+		{"own", idleStrategy{period: 12}, 12},
+		{"indicator", macd, macd.Macd.IdlePeriod()},
+		{"compound", macdRsi, max(macdRsi.MacdStrategy.Macd.IdlePeriod(), macdRsi.RsiStrategy.Rsi.IdlePeriod())},
+		{"empty OR", strategy.NewOrStrategy("OR"), 0},
+		{"nested", or, max(7, rsi.Rsi.IdlePeriod())},
+	}
+	for _, test := range tests {
+		if actual := IdlePeriod(test.st); actual != test.expected {
+			t.Fatalf("%s: actual %d expected %d", test.name, actual, test.expected)
+		}
+	}
+}
+
+func TestHasEnoughData(t *testing.T) {
+	st := idleStrategy{period: 3}
+	tests := []struct {
+		snapshots int
+		expected  bool
+	}{
+		{0, false},
+		{3, false},
+		{4, true},
+	}
+	for _, test := range tests {
+		if actual := HasEnoughData(st, test.snapshots); actual != test.expected {
+			t.Fatalf("%d snapshots: actual %v expected %v", test.snapshots, actual, test.expected)
+		}
+	}
+	if !HasEnoughData(holdStrategy{}, 1) {
+		t.Fatal("expected a strategy without a warm-up to need one snapshot")
+	}
+}